@@ -1,27 +1,47 @@
 package liquid
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"io"
+	"io/fs"
+	"maps"
+	"sync/atomic"
+	"time"
 
 	"github.com/osteele/liquid/filters"
+	"github.com/osteele/liquid/parser"
 	"github.com/osteele/liquid/render"
 	"github.com/osteele/liquid/tags"
+	yaml "gopkg.in/yaml.v2"
 )
 
 // An Engine parses template source into renderable text.
 //
 // An engine can be configured with additional filters and tags.
-type Engine struct{ cfg render.Config }
+type Engine struct {
+	cfg    render.Config
+	date   atomic.Pointer[filters.DateConfig]
+	parsed *templateCache
+}
 
 // NewEngine returns a new Engine.
 func NewEngine() *Engine {
-	e := Engine{render.NewConfig()}
+	e := Engine{cfg: render.NewConfig(), parsed: newTemplateCache(DefaultParseCacheSize)}
+	e.date.Store(&filters.DateConfig{})
 	filters.AddStandardFilters(&e.cfg)
+	filters.AddDateFilters(&e.cfg, e.date.Load)
 	tags.AddStandardTags(e.cfg)
 	return &e
 }
 
-// RegisterBlock defines a block e.g. {% tag %}…{% endtag %}.
+// RegisterBlock defines a block e.g. {% tag %}…{% endtag %}. The block ends
+// at the matching {% endtag %}.
+//
+// The renderer reads the text of the tag line from ctx.TagArgs, and renders
+// the block content with ctx.InnerString or ctx.RenderChildren. The content's
+// unrendered source text is available as ctx.InnerSource.
 func (e *Engine) RegisterBlock(name string, td Renderer) {
 	e.cfg.AddBlock(name).Renderer(func(w io.Writer, ctx render.Context) error {
 		s, err := td(ctx)
@@ -33,11 +53,35 @@ func (e *Engine) RegisterBlock(name string, td Renderer) {
 	})
 }
 
+// FilterNames returns the sorted names of the engine's filters, including
+// those that were defined with RegisterFilter.
+func (e *Engine) FilterNames() []string {
+	return e.cfg.FilterNames()
+}
+
+// TagNames returns the sorted names of the engine's tags, including those
+// that were defined with RegisterTag. Blocks are listed by BlockNames.
+func (e *Engine) TagNames() []string {
+	return e.cfg.TagNames()
+}
+
+// BlockNames returns the sorted names of the engine's blocks, such as "if"
+// and "for", including those that were defined with RegisterBlock. It doesn't
+// list end tags, or clauses such as "else".
+func (e *Engine) BlockNames() []string {
+	return e.cfg.BlockNames()
+}
+
 // RegisterFilter defines a Liquid filter, for use as `{{ value | my_filter }}` or `{{ value | my_filter: arg }}`.
 //
 // A filter is a function that takes at least one input, and returns one or two outputs.
 // If it returns two outputs, the second must have type error.
 //
+// If the function's last parameter has type KeywordArgs, it receives the filter's
+// keyword arguments, as in `{{ value | my_filter: arg, name: value }}`; for example,
+// func(input any, kwargs liquid.KeywordArgs) any. A last parameter of another map
+// type, such as map[string]any, receives a positional argument.
+//
 // Examples:
 //
 // * https://github.com/osteele/liquid/blob/main/filters/standard_filters.go
@@ -47,6 +91,54 @@ func (e *Engine) RegisterFilter(name string, fn any) {
 	e.cfg.AddFilter(name, fn)
 }
 
+// RegisterFilterWithContext defines a Liquid filter that also receives the
+// rendering context, for example in order to read the current locale from
+// the template bindings. Filters that don't need the context should be
+// defined with RegisterFilter.
+func (e *Engine) RegisterFilterWithContext(name string, fn func(ctx render.Context, input any, args ...any) any) {
+	e.cfg.AddContextFilter(name, fn)
+}
+
+// SetMoneyFormat sets the currency format of the money and money_with_currency filters.
+// The default, DefaultMoneyFormat, formats integer cents and float amounts as US dollars.
+//
+// The format is a setting of the engine, and applies to every template that it renders; it
+// can't be set for an individual render. To render in several currencies, use an engine for
+// each format. Call SetMoneyFormat before rendering.
+func (e *Engine) SetMoneyFormat(f MoneyFormat) {
+	filters.AddMoneyFilters(&e.cfg, f)
+}
+
+// SetTimezone sets the time zone that the date filter displays times in, and
+// that it reads date strings that lack a time zone in. A nil loc restores the
+// default, which displays each time in its own time zone.
+//
+// It is safe to call SetTimezone while templates are rendering. Each use of the
+// date filter reads either the previous or the new configuration.
+func (e *Engine) SetTimezone(loc *time.Location) {
+	e.updateDateConfig(func(dc *filters.DateConfig) { dc.Location = loc })
+}
+
+// SetDateLayouts sets additional layouts, in the format of time.Parse, that
+// the date filter tries on strings before its built-in layouts. Like
+// SetTimezone, it is safe to call while templates are rendering.
+func (e *Engine) SetDateLayouts(layouts ...string) {
+	e.updateDateConfig(func(dc *filters.DateConfig) { dc.Layouts = layouts })
+}
+
+// updateDateConfig replaces the configuration of the date filter with a copy
+// that update has modified. Renders never see a partly updated configuration.
+func (e *Engine) updateDateConfig(update func(*filters.DateConfig)) {
+	for {
+		old := e.date.Load()
+		dc := *old
+		update(&dc)
+		if e.date.CompareAndSwap(old, &dc) {
+			return
+		}
+	}
+}
+
 // RegisterTag defines a tag e.g. {% tag %}.
 //
 // Further examples are in https://github.com/osteele/gojekyll/blob/master/tags/tags.go
@@ -66,8 +158,117 @@ func (e *Engine) RegisterTag(name string, td Renderer) {
 }
 
 // StrictVariables causes the renderer to error when the template contains an undefined variable.
+// It is equivalent to SetStrictVariables(true).
 func (e *Engine) StrictVariables() {
-	e.cfg.StrictVariables = true
+	e.SetStrictVariables(true)
+}
+
+// SetStrictVariables controls whether a reference to a variable that isn't in the bindings (and
+// isn't a loop or assigned variable), or to a property that a map, struct, or drop doesn't
+// define, as in page.missing or page["missing"], is a render error. A variable or property whose value is nil is still allowed.
+func (e *Engine) SetStrictVariables(enable bool) {
+	e.cfg.StrictVariables = enable
+}
+
+// SetStrictLoopVariables controls whether an {% assign %} or {% capture %} to forloop or
+// tablerowloop inside a loop, which would change the loop's state, is a render error.
+// Otherwise, such an assignment does nothing. Outside a loop, these are ordinary variables.
+func (e *Engine) SetStrictLoopVariables(enable bool) {
+	e.cfg.StrictLoopVariables = enable
+}
+
+// SetUndefinedHandler sets a function that is called when a variable, or a property of a map,
+// struct, or drop, isn't defined. name is the dotted path of the reference, such as
+// "page.subtitle". If fn returns true, its value is used in place of the missing one;
+// otherwise the reference is handled as it would be without a handler, subject to
+// SetStrictVariables. A nil fn removes the handler.
+func (e *Engine) SetUndefinedHandler(fn func(name string, pos Source) (any, bool)) {
+	if fn == nil {
+		e.cfg.UndefinedHandler = nil
+		return
+	}
+	e.cfg.UndefinedHandler = func(path string, loc parser.SourceLoc) (any, bool) {
+		return fn(path, Source{loc.Pathname, loc.LineNo, loc.ColNo})
+	}
+}
+
+// SetGlobal sets a variable that every template that the engine renders can read, as though it
+// were in the bindings of each render, and in the scope of each partial. A binding with the same
+// name overrides it.
+//
+// Globals are deliberately top-level names, rather than members of a reserved settings
+// namespace: a global reads like any other variable, and no variable name is reserved. For
+// site-wide settings, use a map value, as in {{ settings.title }}.
+//
+// SetGlobal replaces the engine's globals with an updated copy, so a render that is in progress
+// keeps the globals it started with. Call it before rendering from other goroutines.
+func (e *Engine) SetGlobal(name string, value any) {
+	m := maps.Clone(e.cfg.Globals)
+	if m == nil {
+		m = map[string]any{}
+	}
+	m[name] = value
+	e.cfg.Globals = m
+}
+
+// SetStrictFilters controls whether a template that applies an undefined filter is a parse error.
+// The filters in objects and in tag arguments are checked against those that are registered when
+// the template is parsed. Otherwise, an undefined filter is a render error only if it is applied.
+func (e *Engine) SetStrictFilters(enable bool) {
+	e.cfg.StrictFilters = enable
+}
+
+// SetCaseInsensitiveKeys controls whether map keys are matched case-insensitively when the exact key
+// is absent. This applies to both index syntax (`hash["key"]`) and property syntax (`hash.key`).
+// If a map has several keys that differ only by case, the first in sorted order is used.
+func (e *Engine) SetCaseInsensitiveKeys(enable bool) {
+	e.cfg.CaseInsensitiveKeys = enable
+}
+
+// SetMaxIncludeDepth sets how deeply {% include %} and {% render %} tags may nest, so that
+// recursive partials are reported as errors instead of exhausting the stack. The default is
+// 100. Zero or less removes the limit.
+func (e *Engine) SetMaxIncludeDepth(n int) {
+	e.cfg.MaxIncludeDepth = n
+}
+
+// SetMaxOutputSize limits the number of bytes that rendering a template may produce. Once the
+// output grows beyond n bytes, rendering stops with an error whose cause is an
+// OutputLimitError. Zero or less, the default, removes the limit.
+func (e *Engine) SetMaxOutputSize(n int) {
+	e.cfg.MaxOutputSize = n
+}
+
+// RegisterPartialResolver sets the function that the {% include %} and {% render %} tags
+// use to load partial templates, in place of reading them from the file system.
+//
+// The resolver receives the tag's file name, joined to the directory of the template
+// that contains the tag. Partials are parsed once and cached by name; the cache is
+// safe for concurrent use, and is cleared by a subsequent call to RegisterPartialResolver
+// or SetFS.
+func (e *Engine) RegisterPartialResolver(fn func(name string) ([]byte, error)) {
+	e.cfg.SetPartialResolver(fn)
+}
+
+// SetFS causes the {% include %} and {% render %} tags to load partial templates from fsys.
+// See RegisterPartialResolver.
+func (e *Engine) SetFS(fsys fs.FS) {
+	e.RegisterPartialResolver(func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	})
+}
+
+// RenderPartial renders the partial template with the given name, in the isolated
+// scope that the {% render %} tag uses: the partial sees only bindings and the
+// engine's globals. The partial is loaded by the resolver set with
+// RegisterPartialResolver or SetFS, or else from the file system. This is useful
+// for testing a partial without a parent template.
+func (e *Engine) RenderPartial(name string, bindings Bindings) (string, SourceError) {
+	buf := new(bytes.Buffer)
+	if err := render.RenderPartial(buf, name, bindings, e.cfg); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
 }
 
 // ParseTemplate creates a new Template using the engine configuration.
@@ -75,6 +276,32 @@ func (e *Engine) ParseTemplate(source []byte) (*Template, SourceError) {
 	return newTemplate(&e.cfg, source, "", 0)
 }
 
+// ParseCached is like ParseTemplate, but it returns the same Template for
+// identical source, from a cache of the most recently used templates. Parse
+// errors are not cached. Since the cache is keyed by source, the engine
+// should be configured before templates are parsed with ParseCached.
+//
+// It's safe to call ParseCached from multiple goroutines.
+func (e *Engine) ParseCached(source []byte) (*Template, SourceError) {
+	key := sha256.Sum256(source)
+	if tpl, ok := e.parsed.get(key); ok {
+		return tpl, nil
+	}
+	tpl, err := e.ParseTemplate(source)
+	if err != nil {
+		return nil, err
+	}
+	e.parsed.add(key, tpl)
+	return tpl, nil
+}
+
+// SetParseCacheSize sets the number of templates that ParseCached keeps,
+// evicting the least recently used as necessary. The default is
+// DefaultParseCacheSize. Zero disables the cache.
+func (e *Engine) SetParseCacheSize(n int) {
+	e.parsed.setSize(n)
+}
+
 // ParseString creates a new Template using the engine configuration.
 func (e *Engine) ParseString(source string) (*Template, SourceError) {
 	return e.ParseTemplate([]byte(source))
@@ -89,6 +316,66 @@ func (e *Engine) ParseTemplateLocation(source []byte, path string, line int) (*T
 	return newTemplate(&e.cfg, source, path, line)
 }
 
+// ParseWithFrontMatter is like ParseTemplate, but it first removes YAML front matter: a block
+// of lines between a first line "---" and the next line "---". It returns the template, and
+// the front matter as a map that can be merged into the render bindings. If the source has
+// no front matter, the map is empty and the whole source is the template. Line numbers in
+// template errors count the front matter lines.
+func (e *Engine) ParseWithFrontMatter(source []byte) (*Template, map[string]any, SourceError) {
+	fm, body, lines := splitFrontMatter(source)
+	data := map[string]any{}
+	if fm != nil {
+		if err := yaml.Unmarshal(fm, &data); err != nil {
+			loc := parser.Token{SourceLoc: parser.SourceLoc{LineNo: 1}, Source: "front matter"}
+			return nil, nil, parser.WrapError(err, loc)
+		}
+		if data == nil {
+			data = map[string]any{}
+		}
+	}
+	tpl, err := newTemplate(&e.cfg, body, "", lines+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tpl, data, nil
+}
+
+// splitFrontMatter returns the YAML front matter of source, if any, and the remaining source
+// and the number of lines before it. fm is nil if source doesn't start with front matter.
+func splitFrontMatter(source []byte) (fm, body []byte, lines int) {
+	rest, ok := cutLine(source, "---")
+	if !ok {
+		return nil, source, 0
+	}
+	fm, lines = []byte{}, 1
+	for len(rest) > 0 {
+		lines++
+		if after, ok := cutLine(rest, "---"); ok {
+			return fm, after, lines
+		}
+		i := bytes.IndexByte(rest, '\n') + 1
+		if i == 0 {
+			i = len(rest)
+		}
+		fm, rest = append(fm, rest[:i]...), rest[i:]
+	}
+	// the front matter isn't closed
+	return nil, source, 0
+}
+
+// cutLine returns the text after the first line of s, and true, if that line is text.
+func cutLine(s []byte, text string) ([]byte, bool) {
+	rest, ok := bytes.CutPrefix(s, []byte(text))
+	if !ok {
+		return s, false
+	}
+	rest, _ = bytes.CutPrefix(rest, []byte("\r"))
+	if len(rest) == 0 {
+		return rest, true
+	}
+	return bytes.CutPrefix(rest, []byte("\n"))
+}
+
 // ParseAndRender parses and then renders the template.
 func (e *Engine) ParseAndRender(source []byte, b Bindings) ([]byte, SourceError) {
 	tpl, err := e.ParseTemplate(source)
@@ -98,6 +385,16 @@ func (e *Engine) ParseAndRender(source []byte, b Bindings) ([]byte, SourceError)
 	return tpl.Render(b)
 }
 
+// ParseAndRenderContext parses and then renders the template, stopping if ctx is done.
+// See Template.RenderContext.
+func (e *Engine) ParseAndRenderContext(ctx context.Context, source []byte, b Bindings) ([]byte, SourceError) {
+	tpl, err := e.ParseTemplate(source)
+	if err != nil {
+		return nil, err
+	}
+	return tpl.RenderContext(ctx, b)
+}
+
 // ParseAndFRender parses and then renders the template into w.
 func (e *Engine) ParseAndFRender(w io.Writer, source []byte, b Bindings) SourceError {
 	tpl, err := e.ParseTemplate(source)
@@ -3,11 +3,20 @@ package liquid
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	"github.com/osteele/liquid/render"
+	"github.com/osteele/liquid/values"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,6 +28,17 @@ var liquidTests = []struct{ in, expected string }{
 	{`{{ page.title }}`, "Introduction"},
 	{`{% if x %}true{% endif %}`, "true"},
 	{`{{ "upper" | upcase }}`, "UPPER"},
+	{`{% assign a, b = "x,y" | split: "," %}{{ b }}{{ a }}`, "yx"},
+	{`{% assign dr = "Dr. Jones" | begins_with: "Dr" %}{% if dr %}doctor{% endif %}`, "doctor"},
+	{`{% assign sr = "Dr. Jones" | ends_with: "Sr." %}{% unless sr %}junior{% endunless %}`, "junior"},
+	{`{% assign gs = ar | group_by_exp: "s", "s | size" %}{% for g in gs %}{{ g.name }}:{{ g.items | join: "," }};{% endfor %}`, "5:first,third;6:second;"},
+	{`{% if ar | has: "size" %}yes{% else %}no{% endif %}`, "yes"},
+	{`{% echo "upper" | upcase %}`, "UPPER"},
+	{`{% echo ar | join: ", " | prepend: "> " %}`, "> first, second, third"},
+	{"{% liquid\n  for s in ar\n    echo s | upcase | append: ';'\n  endfor\n%}", "FIRST;SECOND;THIRD;"},
+	{`{% if ar | has: "size", 4 %}yes{% else %}no{% endif %}`, "no"},
+	{`{% assign data = json | parse_json %}{{ data.name }} {{ data.tags[1] }} {{ data["tags"].size }} {{ data.n | plus: 1 }}`, "Ann b 2 43"},
+	{`{% assign data = json | parse_json %}{% for t in data.tags %}{{ t }}{% endfor %}`, "ab"},
 }
 
 var testBindings = map[string]any{
@@ -27,6 +47,7 @@ var testBindings = map[string]any{
 	"page": map[string]any{
 		"title": "Introduction",
 	},
+	"json": `{"name": "Ann", "tags": ["a", "b"], "n": 42}`,
 }
 
 func TestEngine_ParseAndRenderString(t *testing.T) {
@@ -89,6 +110,50 @@ func TestEngine_ParseAndRenderString_struct(t *testing.T) {
 	require.Equal(t, "hello", str)
 }
 
+type testAuthor struct{ First, Last string }
+
+func (a *testAuthor) FullName() string { return a.First + " " + a.Last }
+
+type testPost struct{ Author testAuthor }
+
+func TestEngine_ParseAndRenderString_struct_ptr_method(t *testing.T) {
+	engine := NewEngine()
+	for _, post := range []any{&testPost{testAuthor{"Ann", "Lee"}}, testPost{testAuthor{"Ann", "Lee"}}} {
+		str, err := engine.ParseAndRenderString("{{ post.Author.FullName }}", map[string]any{"post": post})
+		require.NoError(t, err)
+		require.Equal(t, "Ann Lee", str)
+	}
+}
+
+type testMethods struct{}
+
+func (testMethods) Fails() (string, error)   { return "", errors.New("lookup failed") }
+func (testMethods) Lookup(key string) string { return key }
+
+func TestEngine_ParseAndRender_method_errors(t *testing.T) {
+	engine := NewEngine()
+	bindings := map[string]any{"obj": testMethods{}}
+	for src, msg := range map[string]string{
+		"line 1\n{{ obj.Fails }}":               `method "Fails": lookup failed`,
+		"line 1\n{% if obj.Fails %}{% endif %}": `method "Fails": lookup failed`,
+		"line 1\n{{ obj.Lookup }}":              `method "Lookup": func(string) string is not callable from a template`,
+		"line 1\n{{ obj.Lookup | upcase }}":     `method "Lookup"`,
+	} {
+		tpl, err := engine.ParseTemplateLocation([]byte(src), "page.html", 1)
+		require.NoError(t, err)
+		_, err = tpl.Render(bindings)
+		require.Errorf(t, err, src)
+		require.Containsf(t, err.Error(), msg, src)
+		require.Equalf(t, "page.html", err.Path(), src)
+		require.Equalf(t, 2, err.LineNumber(), src)
+	}
+	_, err := engine.ParseAndRenderString("{{ obj.Fails }}", bindings)
+	var me MethodError
+	require.True(t, errors.As(err, &me))
+	require.Equal(t, "Fails", me.Name)
+	require.EqualError(t, me.Err, "lookup failed")
+}
+
 func TestEngine_ParseAndRender_errors(t *testing.T) {
 	_, err := NewEngine().ParseAndRenderString("{{ syntax error }}", emptyBindings)
 	require.Error(t, err)
@@ -136,3 +201,768 @@ func TestEngine_ParseTemplateAndCache(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "Foo, Bar", string(result))
 }
+
+func TestEngine_SetCaseInsensitiveKeys(t *testing.T) {
+	bindings := Bindings{"page": map[string]any{"Title": "Introduction", "TITLE": "shadowed"}}
+	engine := NewEngine()
+	out, err := engine.ParseAndRenderString(`[{{ page.title }}]`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "[]", out)
+
+	engine.SetCaseInsensitiveKeys(true)
+	out, err = engine.ParseAndRenderString(`{{ page.title }} {{ page["title"] }} {{ page.Title }}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "shadowed shadowed Introduction", out)
+}
+
+func TestEngine_ParseAndRender_conversion_errors(t *testing.T) {
+	engine := NewEngine()
+	engine.RegisterTag("int", func(c render.Context) (string, error) {
+		return strconv.Itoa(values.ValueOf(c.TagArgs()).Int()), nil
+	})
+	for _, src := range []string{
+		"line 1\n{{ \"x\" | plus: 1 }}",
+		"line 1\n{% int x %}",
+	} {
+		_, err := engine.ParseAndRenderString(src, emptyBindings)
+		require.Errorf(t, err, src)
+		require.Containsf(t, err.Error(), "can't convert string(x)", src)
+	}
+	tpl, err := engine.ParseTemplateLocation([]byte("line 1\n{% int x %}"), "test.liquid", 1)
+	require.NoError(t, err)
+	_, err = tpl.Render(emptyBindings)
+	require.Error(t, err)
+	require.Equal(t, 2, err.LineNumber())
+	require.Equal(t, "test.liquid", err.Path())
+}
+
+func TestEngine_FilterNames(t *testing.T) {
+	engine := NewEngine()
+	names := engine.FilterNames()
+	require.IsIncreasing(t, names)
+	for _, name := range []string{"append", "date", "default", "join", "money", "upcase", "where"} {
+		require.Contains(t, names, name)
+	}
+	require.NotContains(t, names, "my_filter")
+	engine.RegisterFilter("my_filter", func(s string) string { return s })
+	require.Contains(t, engine.FilterNames(), "my_filter")
+	require.Len(t, engine.FilterNames(), len(names)+1)
+
+	require.Equal(t, []string{"assign", "break", "continue", "cycle", "decrement", "echo", "include", "increment", "render"}, engine.TagNames())
+	require.Equal(t, []string{"capture", "case", "comment", "for", "if", "ifchanged", "raw", "tablerow", "unless"}, engine.BlockNames())
+	engine.RegisterTag("my_tag", func(render.Context) (string, error) { return "", nil })
+	engine.RegisterBlock("my_block", func(render.Context) (string, error) { return "", nil })
+	require.Contains(t, engine.TagNames(), "my_tag")
+	require.NotContains(t, engine.TagNames(), "my_block")
+	require.Contains(t, engine.BlockNames(), "my_block")
+	require.NotContains(t, engine.BlockNames(), "endmy_block")
+}
+
+func TestEngine_RegisterFilter_keywordArgs(t *testing.T) {
+	engine := NewEngine()
+	engine.RegisterFilter("shorten", func(s string, n int, kwargs KeywordArgs) string {
+		omission, ok := kwargs["omission"].(string)
+		if !ok {
+			omission = "..."
+		}
+		if len(s) <= n {
+			return s
+		}
+		return s[:n] + omission
+	})
+	engine.RegisterFilter("options", func(input any, kwargs KeywordArgs) string {
+		data, err := json.Marshal(kwargs)
+		require.NoError(t, err)
+		return fmt.Sprint(input, string(data))
+	})
+	bindings := map[string]any{"s": "abcdefgh", "o": "—"}
+	for _, test := range []struct{ in, out string }{
+		{`{{ s | shorten: 3 }}`, "abc..."},
+		{`{{ s | shorten: 3, omission: o }}`, "abc—"},
+		{`{{ s | shorten: 3, omission: "!" | upcase }}`, "ABC!"},
+		{`{{ s | options }}`, "abcdefgh{}"},
+		{`{{ 1 | options: a: 1, b: "x", c: s }}`, `1{"a":1,"b":"x","c":"abcdefgh"}`},
+	} {
+		out, err := engine.ParseAndRenderString(test.in, bindings)
+		require.NoErrorf(t, err, test.in)
+		require.Equalf(t, test.out, out, test.in)
+	}
+	_, err := engine.ParseAndRenderString(`{{ s | options: 1 }}`, bindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "wrong number of arguments")
+
+	// a map parameter that isn't KeywordArgs receives a positional argument
+	engine.RegisterFilter("merge", func(a, b map[string]any) map[string]any {
+		m := map[string]any{}
+		for k, v := range a {
+			m[k] = v
+		}
+		for k, v := range b {
+			m[k] = v
+		}
+		return m
+	})
+	out, err := engine.ParseAndRenderString(`{% assign m = x | merge: y %}{{ m.a }}{{ m.b }}`,
+		map[string]any{"x": map[string]any{"a": 1, "b": 2}, "y": map[string]any{"b": 3}})
+	require.NoError(t, err)
+	require.Equal(t, "13", out)
+	_, err = engine.ParseAndRenderString(`{{ x | merge: b: 1 }}`, map[string]any{"x": map[string]any{}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `unexpected keyword argument \"b\"`)
+}
+
+func TestEngine_parse_json_error(t *testing.T) {
+	tpl, err := NewEngine().ParseTemplateLocation([]byte("line 1\n{{ '{' | parse_json }}"), "page.html", 1)
+	require.NoError(t, err)
+	_, err = tpl.Render(emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `"parse_json"`)
+	require.Equal(t, 2, err.LineNumber())
+}
+
+func TestEngine_ParseWithFrontMatter(t *testing.T) {
+	engine := NewEngine()
+	src := "---\ntitle: Home\ntags: [a, b]\nauthor:\n  name: Ann\n---\n{{ title }} by {{ author.name }}: {{ tags | join: \",\" }}"
+	tpl, fm, err := engine.ParseWithFrontMatter([]byte(src))
+	require.NoError(t, err)
+	require.Equal(t, "Home", fm["title"])
+	require.Equal(t, []any{"a", "b"}, fm["tags"])
+	out, err := tpl.RenderString(fm)
+	require.NoError(t, err)
+	require.Equal(t, "Home by Ann: a,b", out)
+
+	// CRLF line endings, and empty front matter
+	for src, expected := range map[string]string{
+		"---\r\ntitle: Home\r\n---\r\n{{ title }}": "Home",
+		"---\n---\nbody": "body",
+		"---\n---":       "",
+	} {
+		tpl, fm, err := engine.ParseWithFrontMatter([]byte(src))
+		require.NoErrorf(t, err, src)
+		out, err := tpl.RenderString(fm)
+		require.NoErrorf(t, err, src)
+		require.Equalf(t, expected, out, src)
+	}
+
+	// absent front matter
+	for _, src := range []string{"{{ title }}---", "--- \ntitle: x\n", "---\ntitle: x\n", "text\n---\na: 1\n---\n"} {
+		tpl, fm, err := engine.ParseWithFrontMatter([]byte(src))
+		require.NoErrorf(t, err, src)
+		require.Equalf(t, map[string]any{}, fm, src)
+		out, err := tpl.RenderString(map[string]any{"title": "T"})
+		require.NoErrorf(t, err, src)
+		require.Equalf(t, strings.ReplaceAll(src, "{{ title }}", "T"), out, src)
+	}
+
+	// malformed front matter
+	_, _, err = engine.ParseWithFrontMatter([]byte("---\ntitle: [unclosed\n---\nbody"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "yaml")
+	_, _, err = engine.ParseWithFrontMatter([]byte("---\n- a list\n---\nbody"))
+	require.Error(t, err)
+
+	// template errors are located after the front matter
+	_, _, err = engine.ParseWithFrontMatter([]byte("---\ntitle: x\n---\nline 4\n{% if %}"))
+	require.Error(t, err)
+	require.Equal(t, 5, err.LineNumber())
+}
+
+func TestEngine_RegisterFilter_error(t *testing.T) {
+	engine := NewEngine()
+	engine.RegisterFilter("parse_int", func(s string) (int, error) {
+		return strconv.Atoi(s)
+	})
+	out, err := engine.ParseAndRenderString(`{{ "12" | parse_int | plus: 1 }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "13", out)
+
+	tpl, err := engine.ParseTemplateLocation([]byte("ok\n {% assign n = s | parse_int %}"), "page.html", 1)
+	require.NoError(t, err)
+	_, err = tpl.Render(map[string]any{"s": "twelve"})
+	require.Error(t, err)
+	require.Equal(t, 2, err.LineNumber())
+	require.Equal(t, 2, err.ColumnNumber())
+	require.Contains(t, err.Error(), `page.html:2:2: Liquid error: error applying filter "parse_int"`)
+	require.Contains(t, err.Error(), `invalid syntax`)
+
+	require.Panics(t, func() {
+		engine.RegisterFilter("bad", func(s string) (string, string) { return s, s })
+	})
+}
+
+func TestEngine_ParseAndRender_error_location(t *testing.T) {
+	engine := NewEngine()
+	src := "<ul>\n{% for i in (1..2) %}\n  {% if i > 1 %}\n    <li>{{ \"x\" | plus: i }}</li>\n  {% endif %}\n{% endfor %}\n</ul>"
+	tpl, err := engine.ParseTemplateLocation([]byte(src), "template.liquid", 1)
+	require.NoError(t, err)
+	_, err = tpl.Render(emptyBindings)
+	require.Error(t, err)
+	require.Equal(t, "template.liquid", err.Path())
+	require.Equal(t, 4, err.LineNumber())
+	require.Equal(t, 9, err.ColumnNumber())
+	require.True(t, strings.HasPrefix(err.Error(), "template.liquid:4:9: Liquid error: "), err.Error())
+	require.Contains(t, err.Error(), "can't convert string(x)")
+
+	_, err = engine.ParseTemplateLocation([]byte("{% if x %}\n  {% endfor %}\n{% endif %}"), "template.liquid", 1)
+	require.Error(t, err)
+	require.Equal(t, 2, err.LineNumber())
+	require.Equal(t, 3, err.ColumnNumber())
+}
+
+func TestEngine_SetFS(t *testing.T) {
+	engine := NewEngine()
+	engine.SetFS(fstest.MapFS{
+		"header.html":         {Data: []byte(`<h1>{{ title }}</h1>`)},
+		"card.html":           {Data: []byte(`[{{ card }}{{ title }}]`)},
+		"nested.html":         {Data: []byte(`{% include "header.html" %}!`)},
+		"partials/inner.html": {Data: []byte(`inner`)},
+		"partials/outer.html": {Data: []byte(`{% include "inner.html" %}`)},
+	})
+	bindings := Bindings{"title": "Hi"}
+	for _, test := range []struct{ in, expected string }{
+		{`{% include "header.html" %}`, "<h1>Hi</h1>"},
+		{`{% include "nested.html" %}`, "<h1>Hi</h1>!"},
+		{`{% include "partials/outer.html" %}`, "inner"},
+		{`{% render "card.html" with "c" %}`, "[c]"},
+		{`{% render "card.html" for "ab" %}`, "[ab]"},
+	} {
+		out, err := engine.ParseAndRenderString(test.in, bindings)
+		require.NoErrorf(t, err, test.in)
+		require.Equalf(t, test.expected, out, test.in)
+	}
+
+	tpl, err := engine.ParseTemplateLocation([]byte("line 1\n{% include \"missing.html\" %}"), "page.html", 1)
+	require.NoError(t, err)
+	_, err = tpl.Render(bindings)
+	require.Error(t, err)
+	require.ErrorIs(t, err.Cause(), fs.ErrNotExist)
+	require.Equal(t, "page.html", err.Path())
+	require.Equal(t, 2, err.LineNumber())
+}
+
+func TestEngine_SetGlobal(t *testing.T) {
+	engine := NewEngine()
+	engine.SetGlobal("settings", map[string]any{"title": "My Site", "tags": []string{"a", "b"}})
+	engine.SetGlobal("year", 2024)
+	engine.RegisterPartialResolver(func(name string) ([]byte, error) {
+		return []byte(`[{{ settings.title }} {{ x }}]`), nil
+	})
+
+	out, err := engine.ParseAndRenderString(`{{ settings.title }} {{ settings.tags[1] }} {{ settings.tags | size }} {{ year }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "My Site b 2 2024", out)
+
+	out, err = engine.ParseAndRenderString(`{{ year }} {{ settings.title }}`, Bindings{"year": 1999})
+	require.NoError(t, err)
+	require.Equal(t, "1999 My Site", out)
+
+	out, err = engine.ParseAndRenderString(`{% assign year = 2000 %}{{ year }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "2000", out)
+	out, err = engine.ParseAndRenderString(`{{ year }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "2024", out, "assign doesn't change the global")
+
+	out, err = engine.ParseAndRenderString(`{% render "partial", x: 1 %}`, Bindings{"settings": nil})
+	require.NoError(t, err)
+	require.Equal(t, "[My Site 1]", out, "partials see globals, not the bindings of the caller")
+
+	engine.SetStrictVariables(true)
+	_, err = engine.ParseAndRenderString(`{{ year }}`, emptyBindings)
+	require.NoError(t, err)
+
+	tpl, err := engine.ParseString(`{{ year }}`)
+	require.NoError(t, err)
+	globals := engine.cfg.Globals
+	engine.SetGlobal("year", 2025)
+	require.Equal(t, 2024, globals["year"], "SetGlobal doesn't modify the globals of a render in progress")
+	out, err = tpl.RenderString(emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "2025", out)
+}
+
+func TestEngine_RegisterPartialResolver(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	engine := NewEngine()
+	engine.RegisterPartialResolver(func(name string) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[name]++
+		if name != "greeting" {
+			return nil, fmt.Errorf("no partial named %q", name)
+		}
+		return []byte(`Hello, {{ name }}!`), nil
+	})
+	tpl, err := engine.ParseString(`{% render "greeting", name: name %}`)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	outs := make([]string, 10)
+	for i := range outs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outs[i], _ = tpl.RenderString(Bindings{"name": strconv.Itoa(i)})
+		}()
+	}
+	wg.Wait()
+	for i, out := range outs {
+		require.Equal(t, "Hello, "+strconv.Itoa(i)+"!", out)
+	}
+	require.LessOrEqual(t, calls["greeting"], 10)
+
+	calls["greeting"] = 0
+	for range 3 {
+		_, err := tpl.RenderString(Bindings{"name": "again"})
+		require.NoError(t, err)
+	}
+	require.Zero(t, calls["greeting"], "the partial is parsed once")
+
+	_, err = engine.ParseAndRenderString(`{% render "other" %}`, emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `no partial named "other"`)
+}
+
+func TestEngine_RenderPartial(t *testing.T) {
+	errMissing := errors.New("missing partial")
+	engine := NewEngine()
+	engine.SetGlobal("site", "My Site")
+	engine.SetFS(fstest.MapFS{
+		"card.html":          {Data: []byte(`<b>{{ product.title }}</b>{{ outer }} {% render "price.html", amount: product.price %}`)},
+		"price.html":         {Data: []byte(`{{ amount | money }} at {{ site }}`)},
+		"broken.html":        {Data: []byte(`{% if %}`)},
+		"includes/card.html": {Data: []byte(`{% render "price.html", amount: 1 %}`)},
+	})
+
+	out, err := engine.RenderPartial("card.html", Bindings{"product": map[string]any{"title": "Hat", "price": 1950}})
+	require.NoError(t, err)
+	require.Equal(t, "<b>Hat</b> $19.50 at My Site", out)
+
+	_, err = engine.RenderPartial("missing.html", emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing.html")
+
+	_, err = engine.RenderPartial("broken.html", emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "broken.html")
+
+	_, err = engine.RenderPartial("includes/card.html", emptyBindings)
+	require.Error(t, err, "nested partials are relative to the partial")
+
+	engine.RegisterPartialResolver(func(name string) ([]byte, error) {
+		return nil, errMissing
+	})
+	_, err = engine.RenderPartial("card.html", emptyBindings)
+	require.ErrorIs(t, err, errMissing)
+}
+
+func TestEngine_SetMaxIncludeDepth(t *testing.T) {
+	engine := NewEngine()
+	engine.SetFS(fstest.MapFS{
+		"self.html": {Data: []byte(`x{% include "self.html" %}`)},
+		"a.html":    {Data: []byte(`a{% render "b.html" %}`)},
+		"b.html":    {Data: []byte(`b{% include "a.html" %}`)},
+	})
+
+	_, err := engine.ParseAndRenderString(`{% include "self.html" %}`, emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "include depth limit of 100 exceeded")
+
+	engine.SetMaxIncludeDepth(3)
+	_, err = engine.ParseAndRenderString(`{% include "self.html" %}`, emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "include depth limit of 3 exceeded: self.html > self.html > self.html > self.html")
+
+	_, err = engine.ParseAndRenderString(`{% render "a.html" %}`, emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "include depth limit of 3 exceeded: a.html > b.html > a.html > b.html")
+
+	engine.SetFS(fstest.MapFS{"leaf.html": {Data: []byte(`leaf`)}})
+	engine.SetMaxIncludeDepth(1)
+	out, err := engine.ParseAndRenderString(`{% include "leaf.html" %}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "leaf", out)
+}
+
+func TestEngine_SetMaxOutputSize(t *testing.T) {
+	engine := NewEngine()
+	engine.SetMaxOutputSize(100)
+	bindings := map[string]any{"items": make([]int, 1000)}
+
+	out, err := engine.ParseAndRenderString(`{% for i in items %}{{ forloop.index }},{% endfor %}`, bindings)
+	require.Error(t, err)
+	require.Empty(t, out)
+	var limitErr OutputLimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, 100, limitErr.Limit)
+
+	_, err = engine.ParseAndRenderString(`{% for i in items %}{% capture x %}{{ i }}{% endcapture %}{% endfor %}`, bindings)
+	require.NoError(t, err)
+
+	_, err = engine.ParseAndRenderString(`{% capture x %}{% for i in items %}{{ i }}{% endfor %}{% endcapture %}`, bindings)
+	require.ErrorAs(t, err, &limitErr)
+
+	out, err = engine.ParseAndRenderString(`{% for i in items limit: 10 %}{{ i }}{% endfor %}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "0000000000", out)
+}
+
+func TestEngine_SetStrictVariables(t *testing.T) {
+	engine := NewEngine()
+	bindings := map[string]any{"null": nil, "page": map[string]any{"title": "Home"}}
+	for _, src := range []string{`{{ missing }}`, `{{ page.missing }}`, `{{ page["missing"] }}`, `{% if missing %}x{% endif %}`} {
+		out, err := engine.ParseAndRenderString(src, bindings)
+		require.NoErrorf(t, err, src)
+		require.Emptyf(t, out, src)
+	}
+
+	engine.SetStrictVariables(true)
+	for _, src := range []string{
+		`{{ null }}{{ page.title }}{{ page["title"] }}`,
+		`{% assign x = 1 %}{{ x }}`,
+		`{% for item in page %}{{ item }}{{ forloop.index }}{% endfor %}`,
+	} {
+		_, err := engine.ParseAndRenderString(src, bindings)
+		require.NoErrorf(t, err, src)
+	}
+	for src, name := range map[string]string{
+		"line 1\n{{ missing }}":               `"missing"`,
+		"line 1\n{{ page.subtitle }}":         `"page.subtitle"`,
+		"line 1\n{{ page[\"subtitle\"] }}":    `"page[\"subtitle\"]"`,
+		"line 1\n{% if missing %}{% endif %}": `"missing"`,
+	} {
+		tpl, err := engine.ParseTemplateLocation([]byte(src), "page.html", 1)
+		require.NoError(t, err)
+		_, err = tpl.Render(bindings)
+		require.Errorf(t, err, src)
+		require.Containsf(t, err.Error(), "undefined variable "+name, src)
+		require.Equalf(t, 2, err.LineNumber(), src)
+	}
+
+	engine.SetStrictVariables(false)
+	_, err := engine.ParseAndRenderString(`{{ missing }}`, bindings)
+	require.NoError(t, err)
+}
+
+func TestEngine_SetStrictLoopVariables(t *testing.T) {
+	engine := NewEngine()
+	src := "{% assign forloop = 'x' %}{{ forloop }}\n{% for i in (1..2) %}{% assign forloop = 5 %}{{ forloop.index }}{% endfor %}"
+	out, err := engine.ParseAndRenderString(src, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "x\n12", out)
+
+	engine.SetStrictLoopVariables(true)
+	tpl, err := engine.ParseTemplateLocation([]byte(src), "page.html", 1)
+	require.NoError(t, err)
+	_, err = tpl.RenderString(emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `cannot assign to "forloop"`)
+	require.Equal(t, 2, err.LineNumber())
+}
+
+func TestEngine_SetUndefinedHandler(t *testing.T) {
+	engine := NewEngine()
+	var names []string
+	var sources []Source
+	engine.SetUndefinedHandler(func(name string, pos Source) (any, bool) {
+		names = append(names, name)
+		sources = append(sources, pos)
+		if strings.Contains(name, "keep") {
+			return nil, false
+		}
+		return "[missing: " + name + "]", true
+	})
+	bindings := map[string]any{"null": nil, "page": map[string]any{"title": "Home"}}
+
+	tpl, err := engine.ParseTemplateLocation([]byte("{{ missing }}\n{{ page.title }} {{ page.subtitle | upcase }} {{ null }}"), "page.html", 1)
+	require.NoError(t, err)
+	out, err := tpl.RenderString(bindings)
+	require.NoError(t, err)
+	require.Equal(t, "[missing: missing]\nHome [MISSING: PAGE.SUBTITLE] ", out)
+	require.Equal(t, []string{"missing", "page.subtitle"}, names)
+	require.Equal(t, []Source{{"page.html", 1, 1}, {"page.html", 2, 18}}, sources)
+
+	names = nil
+	out, err = engine.ParseAndRenderString(`{{ page.keep.name }}{% if keep %}x{% endif %}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "", out)
+	require.Equal(t, []string{"page.keep", "keep"}, names)
+
+	engine.SetStrictVariables(true)
+	out, err = engine.ParseAndRenderString(`{{ missing }}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "[missing: missing]", out)
+	_, err = engine.ParseAndRenderString(`{{ keep }}`, bindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `undefined variable "keep"`)
+
+	names = nil
+	bindings["items"] = []any{map[string]any{"title": "a"}}
+	bindings["i"] = 0
+	bindings["hash"] = map[string]any{"a b": map[string]any{}}
+	out, err = engine.ParseAndRenderString(`{{ items[0].missing }} {{ items[i].missing }} {{ hash["a b"].missing }}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, `[missing: items[0].missing] [missing: items[i].missing] [missing: hash["a b"].missing]`, out)
+	names = nil
+	_, err = engine.ParseAndRenderString(`{{ items[0].keep }}`, bindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `undefined variable "items[0].keep"`)
+	require.Equal(t, []string{"items[0].keep"}, names)
+
+	engine.SetUndefinedHandler(nil)
+	_, err = engine.ParseAndRenderString(`{{ missing }}`, bindings)
+	require.Error(t, err)
+}
+
+func TestEngine_SetStrictFilters(t *testing.T) {
+	engine := NewEngine()
+	src := "line 1\n{% if false %}{{ x | uppercse }}{% endif %}"
+	out, err := engine.ParseAndRenderString(src, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "line 1\n", out)
+
+	engine.SetStrictFilters(true)
+	for _, src := range []string{
+		"line 1\n{% if false %}{{ x | uppercse }}{% endif %}",
+		"line 1\n{% assign y = x | upcase | uppercse: 2 %}",
+		"line 1\n{% for a in array | uppercse %}{% endfor %}",
+	} {
+		_, err := engine.ParseTemplateLocation([]byte(src), "page.html", 1)
+		require.Errorf(t, err, src)
+		require.Containsf(t, err.Error(), `undefined filter "uppercse"`, src)
+		require.Equalf(t, 2, err.LineNumber(), src)
+		require.Containsf(t, err.Error(), "page.html:2:", src)
+	}
+	out, err = engine.ParseAndRenderString(`{{ "ok" | upcase }}{% assign s = "a|b" | split: "|" %}{{ s | join: "-" }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "OKa-b", out)
+}
+
+func TestEngine_RegisterBlock(t *testing.T) {
+	engine := NewEngine()
+	engine.RegisterBlock("upcase", func(ctx render.Context) (string, error) {
+		s, err := ctx.InnerString()
+		if err != nil {
+			return "", err
+		}
+		if args := ctx.TagArgs(); args != "" {
+			s = strings.Repeat(s, len(strings.Fields(args)))
+		}
+		return strings.ToUpper(s), nil
+	})
+	engine.RegisterBlock("source", func(ctx render.Context) (string, error) {
+		return ctx.InnerSource(), nil
+	})
+	bindings := map[string]any{"x": "a", "xs": []string{"b", "c"}}
+
+	out, err := engine.ParseAndRenderString(`{% upcase %}{{ x }}{% for y in xs %}-{{ y }}{% endfor %}{% endupcase %}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "A-B-C", out)
+
+	out, err = engine.ParseAndRenderString(`{% upcase twice please %}{{ x }}{% upcase %}b{% endupcase %}{% endupcase %}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "ABAB", out)
+
+	_, err = engine.ParseAndRenderString(`{% source %}{% if x %}{% endsource %}`, bindings)
+	require.Error(t, err)
+	out, err = engine.ParseAndRenderString(`{% source %}{% raw %}{{ x }}{% if %}{% endraw %}{{ x | upcase }}{% endsource %}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "{% raw %}{{ x }}{% if %}{% endraw %}{{ x | upcase }}", out)
+
+	_, err = engine.ParseString(`{% upcase %}{{ x }}`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `unterminated "upcase" block`)
+}
+
+func TestEngine_RegisterTag_forloop(t *testing.T) {
+	engine := NewEngine()
+	engine.RegisterTag("index0", func(ctx render.Context) (string, error) {
+		return fmt.Sprint(ctx.Get("forloop").(map[string]any)["index0"]), nil
+	})
+	engine.RegisterTag("loop", func(ctx render.Context) (string, error) {
+		loop := ctx.ForLoop()
+		if loop == nil {
+			return "none", nil
+		}
+		s := fmt.Sprintf("%d/%d", loop.Index, loop.Length)
+		if loop.Parent != nil {
+			s = fmt.Sprintf("%d/%d:%s", loop.Parent.Index, loop.Parent.Length, s)
+		}
+		if loop.Last {
+			s += "!"
+		}
+		return s, nil
+	})
+	out, err := engine.ParseAndRenderString(`{% for x in (1..3) %}{% index0 %}{% endfor %}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "012", out)
+
+	out, err = engine.ParseAndRenderString(`{% loop %} {% for x in (1..2) %}{% loop %} {% for y in (1..2) %}{% loop %} {% endfor %}{% endfor %}{% loop %}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "none 1/2 1/2:1/2 1/2:2/2! 2/2! 2/2:1/2 2/2:2/2! none", out)
+}
+
+func TestEngine_RegisterFilterWithContext(t *testing.T) {
+	translations := map[string]map[string]string{
+		"en": {"hello": "Hello"},
+		"fr": {"hello": "Bonjour"},
+	}
+	engine := NewEngine()
+	engine.RegisterFilterWithContext("t", func(ctx render.Context, input any, args ...any) any {
+		locale, _ := ctx.Get("locale").(string)
+		s := fmt.Sprint(input)
+		if tr, ok := translations[locale][s]; ok {
+			s = tr
+		}
+		for _, arg := range args {
+			s += fmt.Sprint(" ", arg)
+		}
+		return s
+	})
+	src := `{{ "hello" | t }}{% assign x = "hello" | t: name %}, {{ x }}{% for i in (1..1) %} {{ "hello" | t | append: "!" }}{% endfor %}`
+	out, err := engine.ParseAndRenderString(src, map[string]any{"locale": "fr", "name": "Ada"})
+	require.NoError(t, err)
+	require.Equal(t, "Bonjour, Bonjour Ada Bonjour!", out)
+	out, err = engine.ParseAndRenderString(src, map[string]any{"locale": "en", "name": "Ada"})
+	require.NoError(t, err)
+	require.Equal(t, "Hello, Hello Ada Hello!", out)
+}
+
+func TestEngine_SetMoneyFormat(t *testing.T) {
+	engine := NewEngine()
+	bindings := map[string]any{"cents": 123456, "amount": -9876.5, "zero": 0}
+	src := `{{ cents | money }} {{ amount | money_with_currency }} {{ zero | money }}`
+	out, err := engine.ParseAndRenderString(src, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "$1,234.56 -$9,876.50 USD $0.00", out)
+
+	engine.SetMoneyFormat(MoneyFormat{
+		Symbol:             "€",
+		Currency:           "EUR",
+		DecimalSeparator:   ",",
+		ThousandsSeparator: ".",
+		Precision:          2,
+		Cents:              true,
+	})
+	out, err = engine.ParseAndRenderString(src, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "€1.234,56 -€9.876,50 EUR €0,00", out)
+
+	engine.SetMoneyFormat(MoneyFormat{Symbol: "¥", Currency: "JPY", ThousandsSeparator: ","})
+	out, err = engine.ParseAndRenderString(src, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "¥123,456 -¥9,877 JPY ¥0", out)
+}
+
+func TestEngine_SetTimezone(t *testing.T) {
+	engine := NewEngine()
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	engine.SetTimezone(tokyo)
+	bindings := map[string]any{
+		"created_at": time.Date(2024, 10, 27, 14, 48, 44, 0, time.UTC),
+		"unix":       1730040524,
+	}
+	tests := []struct{ in, expected string }{
+		{`{{ created_at | date: "%Y-%m-%d %H:%M %z" }}`, "2024-10-27 23:48 +0900"},
+		{`{{ unix | date: "%H:%M %Z" }}`, "23:48 JST"},
+		{`{{ "2024-10-27T14:48:44Z" | date: "%H:%M" }}`, "23:48"},
+		{`{{ "2024-10-27 14:48" | date: "%H:%M %z" }}`, "14:48 +0900"},
+		{`{{ created_at | date: "%H:%M", "UTC" }}`, "14:48"},
+	}
+	for _, test := range tests {
+		out, err := engine.ParseAndRenderString(test.in, bindings)
+		require.NoErrorf(t, err, test.in)
+		require.Equalf(t, test.expected, out, test.in)
+	}
+
+	_, err = engine.ParseAndRenderString(`{{ "27.10.2024 14:48" | date: "%Y" }}`, bindings)
+	require.Error(t, err)
+	engine.SetDateLayouts("02.01.2006 15:04")
+	out, err := engine.ParseAndRenderString(`{{ "27.10.2024 14:48" | date: "%Y-%m-%d %H:%M %z" }}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "2024-10-27 14:48 +0900", out)
+
+	engine.SetTimezone(nil)
+	out, err = engine.ParseAndRenderString(`{{ created_at | date: "%H:%M %Z" }}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "14:48 UTC", out)
+
+	// these don't replace a date filter that the client registered
+	engine.RegisterFilter("date", func(value any) string { return "custom" })
+	engine.SetTimezone(tokyo)
+	engine.SetDateLayouts()
+	out, err = engine.ParseAndRenderString(`{{ created_at | date }}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "custom", out)
+}
+
+func TestEngine_SetTimezone_concurrent(t *testing.T) {
+	engine := NewEngine()
+	tpl, err := engine.ParseString(`{{ t | date: "%H:%M" }}`)
+	require.NoError(t, err)
+	bindings := map[string]any{"t": time.Date(2024, 10, 27, 14, 48, 0, 0, time.UTC)}
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				engine.SetTimezone(time.FixedZone("X", i*60*60))
+				engine.SetDateLayouts("02.01.2006")
+				return
+			}
+			_, err := tpl.RenderString(bindings)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestEngine_ParseCached(t *testing.T) {
+	engine := NewEngine()
+	parse := func(src string) *Template {
+		tpl, err := engine.ParseCached([]byte(src))
+		require.NoError(t, err)
+		return tpl
+	}
+	a := parse(`{{ "a" }}`)
+	require.Same(t, a.GetRoot(), parse(`{{ "a" }}`).GetRoot())
+	require.NotSame(t, a.GetRoot(), parse(`{{ "b" }}`).GetRoot())
+	out, err := a.RenderString(emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "a", out)
+
+	_, err = engine.ParseCached([]byte(`{% if %}`))
+	require.Error(t, err)
+	_, err = engine.ParseCached([]byte(`{% if %}`))
+	require.Error(t, err)
+
+	engine.SetParseCacheSize(2)
+	b := parse(`{{ "b" }}`)
+	a = parse(`{{ "a" }}`)
+	c := parse(`{{ "c" }}`) // evicts b, the least recently used
+	require.Same(t, a, parse(`{{ "a" }}`))
+	require.Same(t, c, parse(`{{ "c" }}`))
+	require.NotSame(t, b, parse(`{{ "b" }}`))
+
+	engine.SetParseCacheSize(0)
+	require.NotSame(t, parse(`{{ "a" }}`), parse(`{{ "a" }}`))
+
+	engine.SetParseCacheSize(10)
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			src := fmt.Sprintf(`{{ %d }}`, i%5)
+			tpl, err := engine.ParseCached([]byte(src))
+			assert.NoError(t, err)
+			out, err := tpl.RenderString(emptyBindings)
+			assert.NoError(t, err)
+			assert.Equal(t, strconv.Itoa(i%5), out)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -8,6 +8,17 @@ import (
 	"github.com/osteele/liquid/values"
 )
 
+// Drop should be replaced by values.PropertyDrop.
+type Drop = values.PropertyDrop
+
+// Value should be replaced by values.Value.
+type Value = values.Value
+
+// RegisterValueConverter should be replaced by values.RegisterValueConverter.
+func RegisterValueConverter(typ reflect.Type, fn func(any) Value) {
+	values.RegisterValueConverter(typ, fn)
+}
+
 // Convert should be replaced by values.Convert.
 func Convert(value any, typ reflect.Type) (any, error) {
 	return values.Convert(value, typ)
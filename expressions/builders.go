@@ -1,26 +1,56 @@
 package expressions
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/osteele/liquid/values"
 )
 
 func makeRangeExpr(startFn, endFn func(Context) values.Value) func(Context) values.Value {
 	return func(ctx Context) values.Value {
-		a := startFn(ctx).Int()
-		b := endFn(ctx).Int()
+		a := rangeBound(startFn(ctx))
+		b := rangeBound(endFn(ctx))
 		return values.ValueOf(values.NewRange(a, b))
 	}
 }
 
+// rangeBound returns the value of a range bound. As in Shopify, this is
+// an integer or a string that holds one.
+func rangeBound(v values.Value) int {
+	if n, ok := v.Int64(); ok {
+		return int(n)
+	}
+	if s, ok := v.Interface().(string); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+			return n
+		}
+	}
+	panic(InterpreterError(fmt.Sprintf("invalid range bound %#v; expected an integer", v.Interface())))
+}
+
 func makeContainsExpr(e1, e2 func(Context) values.Value) func(Context) values.Value {
 	return func(ctx Context) values.Value {
 		return values.ValueOf(e1(ctx).Contains(e2(ctx)))
 	}
 }
 
-func makeFilter(fn valueFn, name string, args []valueFn) valueFn {
+// filterParams are the arguments of a filter application. Keyword arguments,
+// such as allow_false in default: 1, allow_false: true, follow the others.
+type filterParams struct {
+	positional []valueFn
+	keyword    []keywordArg
+}
+
+type keywordArg struct {
+	name string
+	fn   valueFn
+}
+
+func makeFilter(fn valueFn, name string, params filterParams) valueFn {
 	return func(ctx Context) values.Value {
-		result, err := ctx.ApplyFilter(name, fn, args)
+		result, err := applyFilter(ctx, name, fn, params.positional, params.keyword)
 		if err != nil {
 			panic(FilterError{
 				FilterName: name,
@@ -31,15 +61,101 @@ func makeFilter(fn valueFn, name string, args []valueFn) valueFn {
 	}
 }
 
-func makeIndexExpr(sequenceFn, indexFn func(Context) values.Value) func(Context) values.Value {
+// makeIndexExpr makes the expression for seq[index]. path, if it isn't empty,
+// reports a string index that a map, struct, or drop doesn't define.
+func makeIndexExpr(sequenceFn, indexFn func(Context) values.Value, path string) func(Context) values.Value {
 	return func(ctx Context) values.Value {
-		return sequenceFn(ctx).IndexValue(indexFn(ctx))
+		seq, index := sequenceFn(ctx), indexFn(ctx)
+		var value values.Value
+		if contextConfig(ctx).CaseInsensitiveKeys {
+			value = values.IndexValueFold(seq, index)
+		} else {
+			value = seq.IndexValue(index)
+		}
+		if name, ok := index.Interface().(string); ok && path != "" && value.Interface() == nil && !values.HasProperty(seq, name) {
+			if v, ok := undefinedVariable(ctx, path); ok {
+				return values.ValueOf(v)
+			}
+		}
+		return value
 	}
 }
 
-func makeObjectPropertyExpr(objFn func(Context) values.Value, name string) func(Context) values.Value {
+// makeObjectPropertyExpr makes the expression for obj.name. path is the
+// dotted path (such as page.title) that reports an undefined property.
+func makeObjectPropertyExpr(objFn func(Context) values.Value, name, path string) func(Context) values.Value {
 	index := values.ValueOf(name)
 	return func(ctx Context) values.Value {
-		return objFn(ctx).PropertyValue(index)
+		obj := objFn(ctx)
+		var value values.Value
+		if contextConfig(ctx).CaseInsensitiveKeys {
+			value = values.PropertyValueFold(obj, index)
+		} else {
+			value = obj.PropertyValue(index)
+		}
+		if value.Interface() == nil && !values.HasProperty(obj, name) {
+			if v, ok := undefinedVariable(ctx, path); ok {
+				return values.ValueOf(v)
+			}
+		}
+		return value
+	}
+}
+
+func makeVariableExpr(name string) func(Context) values.Value {
+	return func(ctx Context) values.Value {
+		value, found := lookupVariable(ctx, name)
+		if !found {
+			if v, ok := undefinedVariable(ctx, name); ok {
+				return values.ValueOf(v)
+			}
+		}
+		return values.ValueOf(value)
+	}
+}
+
+// literalText returns the source text of a literal value, such as "a" or 1.
+func literalText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return strconv.Quote(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// indexPath returns the path of seq[index], given the path of seq, and the
+// path or literal source text of index. It's empty if seq doesn't have a path.
+func indexPath(seq, index, literal string) string {
+	switch {
+	case seq == "":
+		return ""
+	case index != "":
+		return seq + "[" + index + "]"
+	case literal != "":
+		return seq + "[" + literal + "]"
+	default:
+		return seq + "[...]"
+	}
+}
+
+// undefinedVariable reports a reference to an undefined variable or property,
+// such as page.title, by its path, if the configuration asks for this. It returns the value that the Undefined
+// handler substitutes, if any.
+func undefinedVariable(ctx Context, path string) (any, bool) {
+	cfg := contextConfig(ctx)
+	if cfg.Undefined != nil {
+		if v, ok := cfg.Undefined(path); ok {
+			return v, true
+		}
+	}
+	switch {
+	case cfg.StrictVariables:
+		panic(UndefinedVariable(path))
+	case cfg.Warn != nil:
+		cfg.Warn(UndefinedVariable(path))
 	}
+	return nil, false
 }
@@ -3,6 +3,29 @@ package expressions
 // Config holds configuration information for expression interpretation.
 type Config struct {
 	filters map[string]any
+	// CaseInsensitiveKeys causes map lookups, by index and by property, to fall back to a
+	// case-insensitive match when the map doesn't contain the exact key.
+	CaseInsensitiveKeys bool
+	// StrictVariables causes evaluation to fail with an UndefinedVariable error when an
+	// expression refers to a variable that isn't bound, or to a property that a map,
+	// struct, or drop doesn't define. Variables and properties whose value is nil are
+	// still allowed.
+	StrictVariables bool
+	// Warn, if set, is called with an UndefinedVariable or UndefinedFilter error where
+	// StrictVariables is not set and the variable or property evaluates to nil, and
+	// where an undefined filter would otherwise be an error. In the latter case the
+	// filter evaluates to its input.
+	Warn func(error)
+	// Undefined, if set, is called with the dotted path, such as page.subtitle, of a
+	// variable or property that isn't defined. If it returns true, the value that it
+	// returns is used instead, and StrictVariables and Warn don't apply.
+	Undefined func(path string) (any, bool)
+	// StrictFilters causes CheckFilters to report filters that haven't been added.
+	StrictFilters bool
+	// FilterContext is passed as the first argument to filters that were added
+	// with AddContextFilter. The render package sets it to the render.Context
+	// of the object or tag that is being evaluated.
+	FilterContext any
 }
 
 // NewConfig creates a new Config.
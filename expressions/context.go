@@ -30,11 +30,32 @@ func (ctx *context) Clone() Context {
 	return &context{ctx.Config, bindings}
 }
 
+// contextConfig returns the configuration of a Context made by NewContext.
+// Other implementations of Context have the zero configuration.
+func contextConfig(ctx Context) *Config {
+	if c, ok := ctx.(*context); ok {
+		return &c.Config
+	}
+	return &Config{}
+}
+
 // Get looks up a variable value in the expression context.
 func (ctx *context) Get(name string) any {
 	return values.ToLiquid(ctx.bindings[name])
 }
 
+// lookupVariable is like ctx.Get, but also reports whether the variable is
+// bound. Other implementations of Context can't tell an undefined variable
+// from one that is bound to nil, so they report every variable as bound.
+func lookupVariable(ctx Context, name string) (any, bool) {
+	c, ok := ctx.(*context)
+	if !ok {
+		return ctx.Get(name), true
+	}
+	value, found := c.bindings[name]
+	return values.ToLiquid(value), found
+}
+
 // Set sets a variable value in the expression context.
 func (ctx *context) Set(name string, value any) {
 	ctx.bindings[name] = value
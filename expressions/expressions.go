@@ -52,10 +52,14 @@ func (e expression) Evaluate(ctx Context) (out any, err error) {
 			switch e := r.(type) {
 			case values.TypeError:
 				err = e
+			case values.MethodError:
+				err = e
 			case InterpreterError:
 				err = e
 			case UndefinedFilter:
 				err = e
+			case UndefinedVariable:
+				err = e
 			case FilterError:
 				err = e
 			case error:
@@ -23,28 +23,31 @@ func init() {
    cyclefn  func(string) Cycle
    loop     Loop
    loopmods loopModifiers
-   filter_params []valueFn
+   filter_params filterParams
+   path     string
+   literal  string
 }
-%type<f> expr rel filtered cond
+%type<f> expr rel filtered cond cond_term
 %type<filter_params> filter_params
 %type<exprs> exprs expr2
 %type<cycle> cycle
 %type<cyclefn> cycle2
-%type<ss> cycle3
+%type<ss> cycle3 idents
 %type<loop> loop
 %type<loopmods> loop_modifiers
 %type<s> string
 %token <val> LITERAL
 %token <name> IDENTIFIER KEYWORD PROPERTY
-%token ASSIGN CYCLE LOOP WHEN
-%token EQ NEQ GE LE IN AND OR CONTAINS DOTDOT
+%token ASSIGN CYCLE LOOP WHEN CONTINUE
+%token EQ NEQ GE LE IN AND OR NOT CONTAINS DOTDOT
 %left '.' '|'
 %left '<' '>'
 %%
 start:
   cond ';' { yylex.(*lexer).val = $1 }
-| ASSIGN IDENTIFIER '=' cond ';' {
-	yylex.(*lexer).Assignment = Assignment{$2, &expression{$4}}
+| ASSIGN IDENTIFIER idents '=' cond ';' {
+	names := append([]string{$2}, $3...)
+	yylex.(*lexer).Assignment = Assignment{$2, names, &expression{$5}}
 }
 | CYCLE cycle ';' { yylex.(*lexer).Cycle = $2 }
 | LOOP loop ';'   { yylex.(*lexer).Loop = $2 }
@@ -69,10 +72,16 @@ cycle3:
 | ',' string cycle3 { $$ = append([]string{$2}, $3...) }
 ;
 
+idents:
+  /* empty */ { $$ = []string{} }
+| ',' IDENTIFIER idents { $$ = append([]string{$2}, $3...) }
+;
+
 exprs: expr expr2 { $$ = append([]Expression{&expression{$1}}, $2...) } ;
 expr2:
   /* empty */    { $$ = []Expression{} }
 | ',' expr expr2 { $$ = append([]Expression{&expression{$2}}, $3...) }
+| OR expr expr2 { $$ = append([]Expression{&expression{$2}}, $3...) }
 ;
 
 string: LITERAL {
@@ -112,27 +121,60 @@ loop_modifiers: /* empty */ { $$ = loopModifiers{} }
 	}
 	$$ = $1
 }
+| loop_modifiers KEYWORD CONTINUE {
+	// the scanner only produces CONTINUE after "offset:"
+	$1.OffsetContinue = true
+	$$ = $1
+}
 ;
 
 expr:
-  LITERAL { val := $1; $$ = func(Context) values.Value { return values.ValueOf(val) } }
-| IDENTIFIER { name := $1; $$ = func(ctx Context) values.Value { return values.ValueOf(ctx.Get(name)) } }
-| expr PROPERTY { $$ = makeObjectPropertyExpr($1, $2) }
-| expr '[' expr ']' { $$ = makeIndexExpr($1, $3) }
-| '(' expr DOTDOT expr ')' { $$ = makeRangeExpr($2, $4) }
-| '(' cond ')' { $$ = $2 }
+  LITERAL {
+	val := $1
+	$$ = func(Context) values.Value { return values.ValueOf(val) }
+	// literal is the source text of a literal, for the path of an index expression
+	$<path>$, $<literal>$ = "", literalText(val)
+}
+| IDENTIFIER { $$ = makeVariableExpr($1); $<path>$, $<literal>$ = $1, "" }
+| expr PROPERTY {
+	// path is the path of a variable or property chain, such as page.title or
+	// items[0].title; it is empty for other expressions.
+	path := $2
+	if $<path>1 != "" {
+		path = $<path>1 + "." + $2
+	}
+	$$ = makeObjectPropertyExpr($1, $2, path)
+	$<path>$, $<literal>$ = path, ""
+}
+| expr '[' expr ']' {
+	path := indexPath($<path>1, $<path>3, $<literal>3)
+	$$ = makeIndexExpr($1, $3, path)
+	$<path>$, $<literal>$ = path, ""
+}
+| '(' expr DOTDOT expr ')' { $$ = makeRangeExpr($2, $4); $<path>$, $<literal>$ = "", "" }
+| '(' cond ')' { $$ = $2; $<path>$, $<literal>$ = "", "" }
 ;
 
 filtered:
   expr
-| filtered '|' IDENTIFIER { $$ = makeFilter($1, $3, nil) }
+| filtered '|' IDENTIFIER { $$ = makeFilter($1, $3, filterParams{}) }
 | filtered '|' KEYWORD filter_params { $$ = makeFilter($1, $3, $4) }
 ;
 
 filter_params:
-  expr { $$ = []valueFn{$1} }
-| filter_params ',' expr
-  { $$ = append($1, $3) }
+  expr { $$ = filterParams{positional: []valueFn{$1}} }
+| KEYWORD expr { $$ = filterParams{keyword: []keywordArg{{$1, $2}}} }
+| filter_params ',' expr {
+	if len($1.keyword) > 0 {
+		panic(SyntaxError("positional filter argument follows keyword argument"))
+	}
+	$1.positional = append($1.positional, $3)
+	$$ = $1
+}
+| filter_params ',' KEYWORD expr {
+	$1.keyword = append($1.keyword, keywordArg{$3, $4})
+	$$ = $1
+}
 
 rel:
   filtered
@@ -181,18 +223,31 @@ rel:
 | expr CONTAINS expr { $$ = makeContainsExpr($1, $3) }
 ;
 
+// As in Shopify, and and or have the same precedence, and group to the right:
+// a and b or c is a and (b or c). Parentheses, which Shopify doesn't allow
+// in conditions, and not bind more tightly.
 cond:
-  rel
-| cond AND rel {
+  cond_term
+| cond_term AND cond {
 	fa, fb := $1, $3
 	$$ = func(ctx Context) values.Value {
 		return values.ValueOf(fa(ctx).Test() && fb(ctx).Test())
 	}
 }
-| cond OR rel {
+| cond_term OR cond {
 	fa, fb := $1, $3
 	$$ = func(ctx Context) values.Value {
 		return values.ValueOf(fa(ctx).Test() || fb(ctx).Test())
 	}
 }
 ;
+
+cond_term:
+  rel
+| NOT cond_term {
+	f := $2
+	$$ = func(ctx Context) values.Value {
+		return values.ValueOf(!f(ctx).Test())
+	}
+}
+;
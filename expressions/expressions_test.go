@@ -46,6 +46,25 @@ var evaluatorTests = []struct {
 	{`hash[1]`, nil},
 	{`hash.c[0]`, "r"},
 
+	// Property chains
+	{`page.sections[0].title`, "one"},
+	{`page["sections"][0]["title"]`, "one"},
+	{`page.sections[1]["items"][-1]`, 3},
+	{`page["sections"][-1].title`, "two"},
+	{`page["sections"][-1]`, map[string]any{"title": "two", "items": []int{2, 3}}},
+	{`page.sections[2].title`, nil},
+	{`page.sections[-3].title`, nil},
+	{`page.sections["0"].title`, nil},
+	{`page.sections.first.title`, "one"},
+	{`page.sections["first"]`, nil},
+	{`page.sections.size`, 2},
+	{`struct.Meta.key`, "value"},
+	{`struct.Meta["key"]`, "value"},
+	{`struct["Meta"]["key"]`, "value"},
+	{`struct_ptr.Meta.key`, "value"},
+	{`struct.Sections[0].title`, "one"},
+	{`struct.Sections[-1]["title"]`, "two"},
+
 	// Range
 	{`(1..5)`, values.NewRange(1, 5)},
 	{`(1..range.end)`, values.NewRange(1, 5)},
@@ -102,22 +121,69 @@ var evaluatorTests = []struct {
 	{`false or false`, false},
 	{`false or true`, true},
 
+	// as in Shopify, and and or group to the right
+	{`true or false and false`, true},
+	{`false and false or true`, false},
+	{`false and true or true`, false},
+	{`true or true and false`, true},
+	// parentheses group conditions
+	{`(true or false) and false`, false},
+	{`(false and false) or true`, true},
+	{`false and (true or true)`, false},
+	{`(n == 123 or n == 1) and "seafood" contains "foo"`, true},
+	{`((n > 200) or (n < 100)) and true`, false},
+	// not applies to the comparison or group that follows it
+	{`not true`, false},
+	{`not false`, true},
+	{`not not true`, true},
+	{`not n == 123`, false},
+	{`not n == 1 and n > 100`, true},
+	{`not false and false`, false},
+	{`not (false and false)`, true},
+	{`not (true or false) or true`, true},
+	{`not "seafood" contains "foo"`, false},
+	{`not missing`, true},
+	{`nothing`, nil},
+	// without an operand after it, not is a variable name
+	{`not`, nil},
+	{`not == nil`, true},
+
 	{`"seafood" contains "foo"`, true},
 	{`"seafood" contains "bar"`, false},
 	{`array contains "first"`, true},
 	{`interface_array contains "first"`, true},
+	{`numbers contains 2.0`, true},
+	{`numbers contains 2.5`, false},
+	{`numbers contains "2"`, false},
 	{`"foo" contains "missing"`, false},
 	{`nil contains "missing"`, false},
+	{`"seafood" contains nil`, false},
+	{`"Grüße" contains "üß"`, true},
+	{`"10.5%" contains 10.5`, true},
 
 	// filters
 	{`"seafood" | length`, 8},
 }
 
+type evaluatorTestStruct struct {
+	Meta     map[string]any
+	Sections []map[string]any
+}
+
+var (
+	testSections = []map[string]any{
+		{"title": "one"},
+		{"title": "two", "items": []int{2, 3}},
+	}
+	testStruct = evaluatorTestStruct{map[string]any{"key": "value"}, testSections}
+)
+
 var evaluatorTestBindings = (map[string]any{
 	"n":               123,
 	"array":           []string{"first", "second", "third"},
 	"interface_array": []any{"first", "second", "third"},
 	"empty_list":      []any{},
+	"numbers":         []any{1, 2, 3},
 	"fruits":          []string{"apples", "oranges", "peaches", "plums"},
 	"hash": map[string]any{
 		"a": "first",
@@ -125,6 +191,9 @@ var evaluatorTestBindings = (map[string]any{
 		"c": []string{"r", "g", "b"},
 	},
 	"hash_with_size_key": map[string]any{"size": "key_value"},
+	"page":               map[string]any{"sections": testSections},
+	"struct":             testStruct,
+	"struct_ptr":         &testStruct,
 	"range": map[string]any{
 		"begin": 1,
 		"end":   5,
@@ -168,3 +237,86 @@ func TestClosure(t *testing.T) {
 	require.Equal(t, 1, x1)
 	require.Equal(t, 2, x2)
 }
+
+func TestEvaluateString_caseInsensitiveKeys(t *testing.T) {
+	bindings := map[string]any{
+		"user": map[string]any{"FirstName": "Ada", "Address": map[string]any{"CITY": "London"}},
+	}
+	cfg := NewConfig()
+	ctx := NewContext(bindings, cfg)
+	val, err := EvaluateString(`user.firstname`, ctx)
+	require.NoError(t, err)
+	require.Nil(t, val)
+
+	cfg.CaseInsensitiveKeys = true
+	ctx = NewContext(bindings, cfg)
+	for _, src := range []string{`user.firstname`, `user["FIRSTNAME"]`, `user.FirstName`} {
+		val, err = EvaluateString(src, ctx)
+		require.NoErrorf(t, err, src)
+		require.Equalf(t, "Ada", val, src)
+	}
+	val, err = EvaluateString(`user.address["city"]`, ctx)
+	require.NoError(t, err)
+	require.Equal(t, "London", val)
+}
+
+func TestEvaluateString_strictVariables(t *testing.T) {
+	bindings := map[string]any{
+		"null": nil,
+		"page": map[string]any{"title": "Home", "author": nil},
+		"list": []int{},
+	}
+	cfg := NewConfig()
+	for _, src := range []string{`missing`, `page.missing`, `missing.title`} {
+		val, err := EvaluateString(src, NewContext(bindings, cfg))
+		require.NoErrorf(t, err, src)
+		require.Nilf(t, val, src)
+	}
+
+	cfg.StrictVariables = true
+	ctx := NewContext(bindings, cfg)
+	for _, src := range []string{`null`, `page.author`, `null.title`, `list.first`, `page.size`} {
+		_, err := EvaluateString(src, ctx)
+		require.NoErrorf(t, err, src)
+	}
+	for src, name := range map[string]string{`missing`: "missing", `page.missing`: "page.missing", `page["missing"]`: `page["missing"]`, `missing.title`: "missing"} {
+		_, err := EvaluateString(src, ctx)
+		require.Errorf(t, err, src)
+		require.IsTypef(t, UndefinedVariable(""), err, src)
+		require.Containsf(t, err.Error(), fmt.Sprintf("%q", name), src)
+	}
+}
+
+// wrapperContext is a Context that wraps another one, as in a mock or a
+// Context that records the variables it reads.
+type wrapperContext struct {
+	Context
+	reads []string
+}
+
+func (ctx *wrapperContext) Get(name string) any {
+	ctx.reads = append(ctx.reads, name)
+	return ctx.Context.Get(name)
+}
+
+func TestEvaluateString_customContext(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddFilter("length", func(s string) int { return len(s) })
+	ctx := &wrapperContext{Context: NewContext(evaluatorTestBindings, cfg)}
+	for src, expected := range map[string]any{
+		`hash.a`:                 "first",
+		`array[1]`:               "second",
+		`missing.title`:          nil,
+		`array[0] | length`:      5,
+		`fruits[0] contains 'p'`: true,
+	} {
+		val, err := EvaluateString(src, ctx)
+		require.NoErrorf(t, err, src)
+		require.Equalf(t, expected, val, src)
+	}
+	require.Subset(t, ctx.reads, []string{"hash", "array", "missing", "fruits"})
+
+	_, err := EvaluateString(`array[0] | length: n: 1`, ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unexpected keyword argument")
+}
@@ -3,6 +3,7 @@ package expressions
 import (
 	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/osteele/liquid/values"
 )
@@ -21,6 +22,15 @@ func (e UndefinedFilter) Error() string {
 	return fmt.Sprintf("undefined filter %q", string(e))
 }
 
+// UndefinedVariable is an error that the named variable or property is not defined.
+// A property is named by its path, such as page.title.
+// It is only raised when Config.StrictVariables is set.
+type UndefinedVariable string
+
+func (e UndefinedVariable) Error() string {
+	return fmt.Sprintf("undefined variable %q", string(e))
+}
+
 // FilterError is the error returned by a filter when it is applied
 type FilterError struct {
 	FilterName string
@@ -33,6 +43,15 @@ func (e FilterError) Error() string {
 
 type valueFn func(Context) values.Value
 
+// KeywordArgs holds the keyword arguments of a filter application, such as
+// allow_false in default: 1, allow_false: true. A filter function whose last
+// parameter has this type receives them there.
+type KeywordArgs map[string]any
+
+// A contextFilter is a filter function whose first parameter receives
+// Config.FilterContext.
+type contextFilter struct{ fn any }
+
 // AddFilter adds a filter to the filter dictionary.
 func (c *Config) AddFilter(name string, fn any) {
 	rf := reflect.ValueOf(fn)
@@ -43,33 +62,129 @@ func (c *Config) AddFilter(name string, fn any) {
 		panic("a filter function must have at least one input")
 	case rf.Type().NumOut() < 1 || 2 < rf.Type().NumOut():
 		panic("a filter must be have one or two outputs")
-		// case rf.Type().Out(1).Implements(…):
-		// 	panic(typeError("a filter's second output must be type error"))
+	case rf.Type().NumOut() == 2 && rf.Type().Out(1) != errorType:
+		panic("a filter's second output must be type error")
+	}
+	c.addFilter(name, fn)
+}
+
+// AddContextFilter adds a filter whose first parameter receives the value of
+// Config.FilterContext where the filter is applied. Its remaining parameters
+// and its results are as for AddFilter.
+func (c *Config) AddContextFilter(name string, fn any) {
+	rf := reflect.ValueOf(fn)
+	switch {
+	case rf.Kind() != reflect.Func:
+		panic("a filter must be a function")
+	case rf.Type().NumIn() < 2:
+		panic("a context filter function must have at least two inputs")
+	case rf.Type().NumOut() < 1 || 2 < rf.Type().NumOut():
+		panic("a filter must be have one or two outputs")
+	case rf.Type().NumOut() == 2 && rf.Type().Out(1) != errorType:
+		panic("a filter's second output must be type error")
 	}
+	c.addFilter(name, contextFilter{fn})
+}
+
+func (c *Config) addFilter(name string, fn any) {
 	if len(c.filters) == 0 {
 		c.filters = make(map[string]any)
 	}
 	c.filters[name] = fn
 }
 
+// FilterNames returns the sorted names of the filters that have been added.
+func (c *Config) FilterNames() []string {
+	names := make([]string, 0, len(c.filters))
+	for name := range c.filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckFilters returns an UndefinedFilter error for the first filter in source
+// that hasn't been added, if c.StrictFilters is set. It scans rather than parses
+// source, so it can be applied to the arguments of any tag.
+func (c *Config) CheckFilters(source string) error {
+	if !c.StrictFilters {
+		return nil
+	}
+	var (
+		lex  = newLexer([]byte(source))
+		sym  yySymType
+		prev int
+	)
+	for {
+		tok := lex.Lex(&sym)
+		if tok == 0 {
+			return nil
+		}
+		if prev == '|' && (tok == IDENTIFIER || tok == KEYWORD) {
+			if _, ok := c.filters[sym.name]; !ok {
+				return UndefinedFilter(sym.name)
+			}
+		}
+		prev = tok
+	}
+}
+
 var (
 	closureType   = reflect.TypeOf(closure{})
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
 	interfaceType = reflect.TypeOf([]any{}).Elem()
+	kwargsType    = reflect.TypeOf(KeywordArgs{})
 )
 
 func isClosureInterfaceType(t reflect.Type) bool {
 	return closureType.ConvertibleTo(t) && !interfaceType.ConvertibleTo(t)
 }
 
+// acceptsKeywordArgs reports whether a filter function's last parameter
+// receives keyword arguments.
+func acceptsKeywordArgs(t reflect.Type) bool {
+	return !t.IsVariadic() && t.NumIn() > 1 && t.In(t.NumIn()-1) == kwargsType
+}
+
+// ApplyFilter applies the named filter to the receiver and positional arguments.
 func (ctx *context) ApplyFilter(name string, receiver valueFn, params []valueFn) (any, error) {
+	return ctx.applyFilter(name, receiver, params, nil)
+}
+
+// applyFilter applies a filter with keyword arguments in ctx. Other
+// implementations of Context only apply filters without keyword arguments.
+func applyFilter(ctx Context, name string, receiver valueFn, params []valueFn, kwparams []keywordArg) (any, error) {
+	if c, ok := ctx.(*context); ok {
+		return c.applyFilter(name, receiver, params, kwparams)
+	}
+	if len(kwparams) > 0 {
+		return nil, fmt.Errorf("unexpected keyword argument %q", kwparams[0].name)
+	}
+	return ctx.ApplyFilter(name, receiver, params)
+}
+
+// applyFilter is ApplyFilter, with keyword arguments. These are passed as
+// a map to a filter function whose last parameter is a KeywordArgs.
+func (ctx *context) applyFilter(name string, receiver valueFn, params []valueFn, kwparams []keywordArg) (any, error) {
 	filter, ok := ctx.filters[name]
 	if !ok {
-		panic(UndefinedFilter(name))
+		if ctx.Warn == nil {
+			panic(UndefinedFilter(name))
+		}
+		ctx.Warn(UndefinedFilter(name))
+		return receiver(ctx).Interface(), nil
+	}
+	var args []any
+	if cf, ok := filter.(contextFilter); ok {
+		filter = cf.fn
+		args = append(args, ctx.FilterContext)
 	}
 	fr := reflect.ValueOf(filter)
-	args := []any{receiver(ctx).Interface()}
+	// skip is the number of arguments that don't come from the template
+	skip := len(args)
+	args = append(args, receiver(ctx).Interface())
 	for i, param := range params {
-		if i+1 < fr.Type().NumIn() && isClosureInterfaceType(fr.Type().In(i+1)) {
+		if n := skip + i + 1; n < fr.Type().NumIn() && isClosureInterfaceType(fr.Type().In(n)) {
 			expr, err := Parse(param(ctx).Interface().(string))
 			if err != nil {
 				panic(err)
@@ -79,10 +194,25 @@ func (ctx *context) ApplyFilter(name string, receiver valueFn, params []valueFn)
 			args = append(args, param(ctx).Interface())
 		}
 	}
-	out, err := values.Call(fr, args)
+	var (
+		out any
+		err error
+	)
+	if acceptsKeywordArgs(fr.Type()) {
+		kwargs := make(map[string]any, len(kwparams))
+		for _, kw := range kwparams {
+			kwargs[kw.name] = kw.fn(ctx).Interface()
+		}
+		out, err = values.CallWithKeywordArgs(fr, args, kwargs)
+	} else {
+		if len(kwparams) > 0 {
+			return nil, fmt.Errorf("unexpected keyword argument %q", kwparams[0].name)
+		}
+		out, err = values.Call(fr, args)
+	}
 	if err != nil {
 		if e, ok := err.(*values.CallParityError); ok {
-			err = &values.CallParityError{NumArgs: e.NumArgs - 1, NumParams: e.NumParams - 1}
+			err = &values.CallParityError{NumArgs: e.NumArgs - skip - 1, NumParams: e.NumParams - skip - 1}
 		}
 		return nil, err
 	}
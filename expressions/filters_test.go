@@ -14,7 +14,7 @@ func TestContext_AddFilter(t *testing.T) {
 	require.NotPanics(t, func() { cfg.AddFilter("f", func(int) (a int, e error) { return }) })
 	require.Panics(t, func() { cfg.AddFilter("f", func() int { return 0 }) })
 	require.Panics(t, func() { cfg.AddFilter("f", func(int) {}) })
-	// require.Panics(t, func() { cfg.AddFilter("f", func(int) (a int, b int) { return }) })
+	require.Panics(t, func() { cfg.AddFilter("f", func(int) (a int, b int) { return }) })
 	//nolint:stylecheck
 	require.Panics(t, func() { cfg.AddFilter("f", func(int) (a int, e error, b int) { return }) })
 	require.Panics(t, func() { cfg.AddFilter("f", 10) })
@@ -71,3 +71,59 @@ func TestContext_runFilter(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "(self, 11)", out)
 }
+
+func TestContext_AddContextFilter(t *testing.T) {
+	cfg := NewConfig()
+	require.Panics(t, func() { cfg.AddContextFilter("f", func(any) int { return 0 }) })
+	require.Panics(t, func() { cfg.AddContextFilter("f", 10) })
+	cfg.AddContextFilter("f", func(fc any, s string, args ...any) string {
+		return fmt.Sprint(fc, ":", s, args)
+	})
+	cfg.AddContextFilter("g", func(fc any, s, arg string) string { return s })
+	cfg.FilterContext = "fc"
+	receiver := func(Context) values.Value { return values.ValueOf("self") }
+	arg := func(Context) values.Value { return values.ValueOf(1) }
+	ctx := NewContext(map[string]any{}, cfg)
+	out, err := ctx.ApplyFilter("f", receiver, []valueFn{arg, arg})
+	require.NoError(t, err)
+	require.Equal(t, "fc:self[1 1]", out)
+
+	_, err = ctx.ApplyFilter("g", receiver, []valueFn{arg, arg})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "given 2")
+	require.Contains(t, err.Error(), "expected 1")
+}
+
+func TestContext_keywordArgs(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddFilter("kw", func(s string, kwargs KeywordArgs) string {
+		return fmt.Sprint(s, kwargs)
+	})
+	cfg.AddFilter("mixed", func(s string, n int, sep func(string) string, kwargs KeywordArgs) string {
+		return fmt.Sprint(s, n, sep(","), kwargs)
+	})
+	cfg.AddFilter("positional", func(s string, n int) string { return s })
+	cfg.AddFilter("map", func(s string, m map[string]any) string { return fmt.Sprint(s, m) })
+	ctx := NewContext(map[string]any{"x": 10, "m": map[string]any{"k": "v"}}, cfg)
+	evaluate := func(source string) any {
+		value, err := EvaluateString(source, ctx)
+		require.NoError(t, err, source)
+		return value
+	}
+	require.Equal(t, "amap[]", evaluate(`"a" | kw`))
+	require.Equal(t, "amap[omission:… size:10]", evaluate(`"a" | kw: size: x, omission: "…"`))
+	require.Equal(t, "a1,map[]", evaluate(`"a" | mixed: 1`))
+	require.Equal(t, "a1;map[b:true]", evaluate(`"a" | mixed: 1, ";", b: true`))
+	require.Equal(t, "a2,map[b:<nil> c:10]", evaluate(`"a" | mixed: 2, b: nil, c: x`))
+	require.Equal(t, "amap[]", evaluate(`"a" | map`))
+	require.Equal(t, "amap[k:v]", evaluate(`"a" | map: m`))
+
+	for _, source := range []string{`"a" | positional: 1, b: 2`, `"a" | map: b: 2`} {
+		_, err := EvaluateString(source, ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `unexpected keyword argument \"b\"`)
+	}
+	_, err := EvaluateString(`"a" | mixed: 1, ";", ".", b: 2`, ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "wrong number of arguments")
+}
@@ -31,6 +31,7 @@ var parseErrorTests = []struct{ in, expected string }{
 	{`%cycle 'a' 'b'`, "syntax error"},
 	{`%loop a in in`, "syntax error"},
 	{`%when a b`, "syntax error"},
+	{`a | add: b: 1, 2`, "positional filter argument follows keyword argument"},
 }
 
 // Since the parser returns funcs, there's no easy way to test them except evaluation
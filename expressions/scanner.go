@@ -1,7 +1,11 @@
 //line scanner.rl:1
 package expressions
 
-import "strconv"
+import (
+	"bytes"
+	"strconv"
+	"unicode"
+)
 
 //line scanner.go:9
 var _expression_actions []byte = []byte{
@@ -218,6 +222,7 @@ type lexer struct {
 	data        []byte
 	p, pe, cs   int
 	ts, te, act int
+	keyword     string // the name of the preceding token, if it was a KEYWORD
 }
 
 func (l *lexer) token() string {
@@ -660,9 +665,51 @@ func (lex *lexer) Lex(out *yySymType) int {
 
 //line scanner.rl:119
 
+	// "offset: continue" is a loop modifier, not a reference to a variable named "continue"
+	if tok == IDENTIFIER && out.name == "continue" && lex.keyword == "offset" {
+		tok = CONTINUE
+	}
+	// "not" is an operator when an operand follows it. This is an extension to
+	// Shopify Liquid. Elsewhere, as in {{ not }} or {% assign not = 1 %}, it is
+	// still a variable name.
+	if tok == IDENTIFIER && out.name == "not" && startsOperand(lex.data[lex.te:]) {
+		tok = NOT
+	}
+	lex.keyword = ""
+	if tok == KEYWORD {
+		lex.keyword = out.name
+	}
 	return tok
 }
 
+// startsOperand reports whether the source, after any spaces, begins with a
+// variable, a literal, a group, or another "not".
+func startsOperand(data []byte) bool {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if len(data) == 0 {
+		return false
+	}
+	c := data[0]
+	switch {
+	case c == '_' || unicode.IsLetter(rune(c)):
+		n := bytes.IndexFunc(data, func(r rune) bool {
+			return !(r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r))
+		})
+		if n < 0 {
+			n = len(data)
+		}
+		switch string(data[:n]) {
+		case "and", "or", "contains", "in":
+			return false
+		}
+		return true
+	case c == '-':
+		return len(data) > 1 && '0' <= data[1] && data[1] <= '9'
+	default:
+		return ('0' <= c && c <= '9') || c == '"' || c == '\'' || c == '('
+	}
+}
+
 func (lex *lexer) Error(e string) {
 	// fmt.Println("scan error:", e)
 }
@@ -48,6 +48,25 @@ func TestLex(t *testing.T) {
 	ts = scanExpression("falsehood")
 	require.Len(t, ts, 1)
 
+	ts = scanExpression("not nothing")
+	require.Len(t, ts, 2)
+	require.Equal(t, NOT, ts[0].tok)
+	require.Equal(t, IDENTIFIER, ts[1].tok)
+
+	// "not" without an operand after it is a variable name
+	ts = scanExpression("not")
+	require.Len(t, ts, 1)
+	require.Equal(t, IDENTIFIER, ts[0].tok)
+	ts = scanExpression("not == 1")
+	require.Equal(t, IDENTIFIER, ts[0].tok)
+	ts = scanExpression("not.size")
+	require.Equal(t, IDENTIFIER, ts[0].tok)
+	ts = scanExpression("not and x")
+	require.Equal(t, IDENTIFIER, ts[0].tok)
+	ts = scanExpression("not not x")
+	require.Equal(t, NOT, ts[0].tok)
+	require.Equal(t, NOT, ts[1].tok)
+
 	ts = scanExpression("a.b-c")
 	require.Len(t, ts, 2)
 	require.Equal(t, PROPERTY, ts[1].tok)
@@ -17,7 +17,9 @@ type Statement struct{ parseValue }
 // An Assignment is a parse of an {% assign %} statement
 type Assignment struct {
 	Variable string
-	ValueFn  Expression
+	// Variables are the names in {% assign a, b = … %}. Variable is the first of these.
+	Variables []string
+	ValueFn   Expression
 }
 
 // A Cycle is a parse of an {% assign %} statement
@@ -34,10 +36,11 @@ type Loop struct {
 }
 
 type loopModifiers struct {
-	Limit    Expression
-	Offset   Expression
-	Cols     Expression
-	Reversed bool
+	Limit          Expression
+	Offset         Expression
+	Cols           Expression
+	Reversed       bool
+	OffsetContinue bool // offset: continue
 }
 
 // A When is a parse of a {% when %} clause
@@ -16,6 +16,11 @@ func TestParseStatement(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "a", stmt.Assignment.Variable)
 
+	stmt, err = ParseStatement(AssignStatementSelector, "a, b,c = d")
+	require.NoError(t, err)
+	require.Equal(t, "a", stmt.Assignment.Variable)
+	require.Equal(t, []string{"a", "b", "c"}, stmt.Assignment.Variables)
+
 	stmt, err = ParseStatement(CycleStatementSelector, "'a', 'b'")
 	require.NoError(t, err)
 	require.Equal(t, "", stmt.Cycle.Group)
@@ -38,8 +43,19 @@ func TestParseStatement(t *testing.T) {
 	require.Implements(t, (*Expression)(nil), stmt.Loop.Limit)
 	require.NotNil(t, stmt.Loop.Offset)
 	require.Implements(t, (*Expression)(nil), stmt.Loop.Offset)
+	require.False(t, stmt.Loop.OffsetContinue)
+
+	stmt, err = ParseStatement(LoopStatementSelector, "x in array offset: continue limit: 3")
+	require.NoError(t, err)
+	require.True(t, stmt.Loop.OffsetContinue)
+	require.Nil(t, stmt.Loop.Offset)
+	require.NotNil(t, stmt.Loop.Limit)
 
 	stmt, err = ParseStatement(WhenStatementSelector, "a, b")
 	require.NoError(t, err)
 	require.Len(t, stmt.When.Exprs, 2)
+
+	stmt, err = ParseStatement(WhenStatementSelector, "a or b, c")
+	require.NoError(t, err)
+	require.Len(t, stmt.When.Exprs, 3)
 }
@@ -30,7 +30,9 @@ type yySymType struct {
 	cyclefn       func(string) Cycle
 	loop          Loop
 	loopmods      loopModifiers
-	filter_params []valueFn
+	filter_params filterParams
+	path          string
+	literal       string
 }
 
 const LITERAL = 57346
@@ -41,15 +43,17 @@ const ASSIGN = 57350
 const CYCLE = 57351
 const LOOP = 57352
 const WHEN = 57353
-const EQ = 57354
-const NEQ = 57355
-const GE = 57356
-const LE = 57357
-const IN = 57358
-const AND = 57359
-const OR = 57360
-const CONTAINS = 57361
-const DOTDOT = 57362
+const CONTINUE = 57354
+const EQ = 57355
+const NEQ = 57356
+const GE = 57357
+const LE = 57358
+const IN = 57359
+const AND = 57360
+const OR = 57361
+const NOT = 57362
+const CONTAINS = 57363
+const DOTDOT = 57364
 
 var yyToknames = [...]string{
 	"$end",
@@ -63,6 +67,7 @@ var yyToknames = [...]string{
 	"CYCLE",
 	"LOOP",
 	"WHEN",
+	"CONTINUE",
 	"EQ",
 	"NEQ",
 	"GE",
@@ -70,6 +75,7 @@ var yyToknames = [...]string{
 	"IN",
 	"AND",
 	"OR",
+	"NOT",
 	"CONTAINS",
 	"DOTDOT",
 	"'.'",
@@ -101,78 +107,84 @@ var yyExca = [...]int8{
 
 const yyPrivate = 57344
 
-const yyLast = 117
+const yyLast = 150
 
 var yyAct = [...]int8{
-	9, 47, 42, 18, 8, 2, 76, 23, 14, 15,
-	10, 11, 43, 34, 3, 4, 5, 6, 35, 25,
-	38, 10, 11, 60, 41, 43, 46, 51, 52, 53,
-	54, 55, 56, 57, 58, 25, 25, 12, 44, 39,
-	25, 26, 69, 24, 61, 62, 65, 63, 12, 66,
-	64, 68, 45, 21, 7, 16, 48, 26, 26, 19,
-	70, 77, 26, 14, 15, 72, 73, 1, 75, 36,
-	37, 71, 78, 79, 74, 20, 25, 80, 14, 15,
-	81, 27, 28, 31, 32, 40, 13, 17, 33, 59,
-	49, 50, 30, 29, 25, 22, 67, 0, 26, 27,
-	28, 31, 32, 0, 0, 0, 33, 0, 0, 0,
-	30, 29, 0, 0, 0, 0, 26,
+	11, 49, 44, 2, 65, 39, 10, 23, 86, 18,
+	45, 12, 13, 12, 13, 37, 40, 28, 38, 95,
+	12, 13, 93, 43, 45, 66, 89, 48, 52, 53,
+	56, 57, 58, 59, 60, 61, 62, 63, 28, 28,
+	14, 29, 14, 28, 88, 46, 41, 15, 71, 14,
+	51, 72, 73, 68, 70, 69, 75, 12, 13, 76,
+	47, 50, 29, 29, 77, 78, 27, 29, 24, 25,
+	79, 81, 82, 80, 84, 85, 67, 87, 90, 91,
+	1, 21, 28, 7, 54, 55, 14, 92, 30, 31,
+	34, 35, 94, 26, 96, 16, 36, 64, 12, 13,
+	33, 32, 3, 4, 5, 6, 29, 83, 19, 20,
+	28, 42, 17, 22, 9, 74, 30, 31, 34, 35,
+	12, 13, 8, 0, 36, 0, 0, 14, 33, 32,
+	0, 0, 0, 0, 29, 0, 9, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 14,
 }
 
 var yyPact = [...]int16{
-	6, -1000, 61, 50, 55, 48, 17, -1000, 21, 87,
-	-1000, -1000, 17, -1000, 17, 17, -6, 14, -3, -1000,
-	13, 36, 1, 28, 85, -1000, 17, 17, 17, 17,
-	17, 17, 17, 17, 69, -9, -1000, -1000, 17, -1000,
-	-1000, 55, -1000, 55, -1000, 17, -1000, -1000, 17, -1000,
-	17, 12, 33, 33, 33, 33, 33, 33, 33, 17,
-	-1000, 46, -16, -16, 21, 33, 28, -22, 33, -1000,
-	29, -1000, -1000, -1000, 67, -1000, 17, -1000, -1000, 17,
-	33, 33,
+	94, -32768, 20, 90, 104, 76, 9, 50, -32768, 116,
+	42, 103, -32768, -32768, 116, -32768, -14, 19, -6, -32768,
+	18, 43, 0, 31, 116, 116, -32768, 79, -32768, 9,
+	9, 9, 9, 9, 9, 9, 9, 75, -30, -3,
+	71, -32768, -32768, 104, -32768, 104, -32768, 9, -32768, -32768,
+	9, 9, -32768, -32768, -32768, 53, 32, 36, 36, 36,
+	36, 36, 36, 36, 9, -32768, 116, -14, -20, -20,
+	42, 36, 31, 31, -22, 36, 9, -32768, 10, -1,
+	-32768, -32768, -32768, 73, -32768, -32768, 16, 36, -32768, -32768,
+	-32768, 7, 36, 9, 36, -32768, 36,
 }
 
 var yyPgo = [...]int8{
-	0, 0, 54, 4, 5, 96, 95, 1, 87, 85,
-	2, 75, 74, 3, 67,
+	0, 0, 122, 6, 3, 83, 115, 113, 1, 112,
+	111, 2, 5, 109, 107, 9, 80,
 }
 
 var yyR1 = [...]int8{
-	0, 14, 14, 14, 14, 14, 8, 9, 9, 10,
-	10, 6, 7, 7, 13, 11, 12, 12, 12, 1,
-	1, 1, 1, 1, 1, 3, 3, 3, 5, 5,
-	2, 2, 2, 2, 2, 2, 2, 2, 4, 4,
-	4,
+	0, 16, 16, 16, 16, 16, 9, 10, 10, 11,
+	11, 12, 12, 7, 8, 8, 8, 15, 13, 14,
+	14, 14, 14, 1, 1, 1, 1, 1, 1, 3,
+	3, 3, 6, 6, 6, 6, 2, 2, 2, 2,
+	2, 2, 2, 2, 4, 4, 4, 5, 5,
 }
 
 var yyR2 = [...]int8{
-	0, 2, 5, 3, 3, 3, 2, 3, 1, 0,
-	3, 2, 0, 3, 1, 4, 0, 2, 3, 1,
-	1, 2, 4, 5, 3, 1, 3, 4, 1, 3,
-	1, 3, 3, 3, 3, 3, 3, 3, 1, 3,
-	3,
+	0, 2, 6, 3, 3, 3, 2, 3, 1, 0,
+	3, 0, 3, 2, 0, 3, 3, 1, 4, 0,
+	2, 3, 3, 1, 1, 2, 4, 5, 3, 1,
+	3, 4, 1, 2, 3, 4, 1, 3, 3, 3,
+	3, 3, 3, 3, 1, 3, 3, 1, 2,
 }
 
 var yyChk = [...]int16{
-	-1000, -14, -4, 8, 9, 10, 11, -2, -3, -1,
-	4, 5, 31, 25, 17, 18, 5, -8, -13, 4,
-	-11, 5, -6, -1, 22, 7, 29, 12, 13, 24,
-	23, 14, 15, 19, -1, -4, -2, -2, 26, 25,
-	-9, 27, -10, 28, 25, 16, 25, -7, 28, 5,
-	6, -1, -1, -1, -1, -1, -1, -1, -1, 20,
-	32, -4, -13, -13, -3, -1, -1, -5, -1, 30,
-	-1, 25, -10, -10, -12, -7, 28, 32, 5, 6,
-	-1, -1,
+	-32768, -16, -4, 8, 9, 10, 11, -5, -2, 20,
+	-3, -1, 4, 5, 33, 27, 5, -9, -15, 4,
+	-13, 5, -7, -1, 18, 19, -5, 24, 7, 31,
+	13, 14, 26, 25, 15, 16, 21, -1, -4, -12,
+	30, 27, -10, 29, -11, 30, 27, 17, 27, -8,
+	30, 19, -4, -4, 5, 6, -1, -1, -1, -1,
+	-1, -1, -1, -1, 22, 34, 28, 5, -15, -15,
+	-3, -1, -1, -1, -6, -1, 6, 32, -1, -4,
+	-12, -11, -11, -14, -8, -8, 30, -1, 34, 27,
+	5, 6, -1, 6, -1, 12, -1,
 }
 
 var yyDef = [...]int8{
-	0, -2, 0, 0, 0, 0, 0, 38, 30, 25,
-	19, 20, 0, 1, 0, 0, 0, 0, 9, 14,
-	0, 0, 0, 12, 0, 21, 0, 0, 0, 0,
-	0, 0, 0, 0, 25, 0, 39, 40, 0, 3,
-	6, 0, 8, 0, 4, 0, 5, 11, 0, 26,
-	0, 0, 31, 32, 33, 34, 35, 36, 37, 0,
-	24, 0, 9, 9, 16, 25, 12, 27, 28, 22,
-	0, 2, 7, 10, 15, 13, 0, 23, 17, 0,
-	29, 18,
+	0, -2, 0, 0, 0, 0, 0, 44, 47, 0,
+	36, 29, 23, 24, 0, 1, 11, 0, 9, 17,
+	0, 0, 0, 14, 0, 0, 48, 0, 25, 0,
+	0, 0, 0, 0, 0, 0, 0, 29, 0, 0,
+	0, 3, 6, 0, 8, 0, 4, 0, 5, 13,
+	0, 0, 45, 46, 30, 0, 0, 37, 38, 39,
+	40, 41, 42, 43, 0, 28, 0, 11, 9, 9,
+	19, 29, 14, 14, 31, 32, 0, 26, 0, 0,
+	12, 7, 10, 18, 15, 16, 0, 33, 27, 2,
+	20, 0, 34, 0, 21, 22, 35,
 }
 
 var yyTok1 = [...]int8{
@@ -180,20 +192,21 @@ var yyTok1 = [...]int8{
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	31, 32, 3, 3, 28, 3, 21, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 27, 25,
-	23, 26, 24, 3, 3, 3, 3, 3, 3, 3,
+	33, 34, 3, 3, 30, 3, 23, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 29, 27,
+	25, 28, 26, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 29, 3, 30, 3, 3, 3, 3, 3, 3,
+	3, 31, 3, 32, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 22,
+	3, 3, 3, 3, 24,
 }
 
 var yyTok2 = [...]int8{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
-	12, 13, 14, 15, 16, 17, 18, 19, 20,
+	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
+	22,
 }
 
 var yyTok3 = [...]int8{
@@ -239,7 +252,7 @@ func yyNewParser() yyParser {
 	return &yyParserImpl{}
 }
 
-const yyFlag = -1000
+const yyFlag = -32768
 
 func yyTokname(c int) string {
 	if c >= 1 && c-1 < len(yyToknames) {
@@ -539,87 +552,106 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line expressions.y:45
+//line expressions.y:47
 		{
 			yylex.(*lexer).val = yyDollar[1].f
 		}
 	case 2:
-		yyDollar = yyS[yypt-5 : yypt+1]
-//line expressions.y:46
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line expressions.y:48
 		{
-			yylex.(*lexer).Assignment = Assignment{yyDollar[2].name, &expression{yyDollar[4].f}}
+			names := append([]string{yyDollar[2].name}, yyDollar[3].ss...)
+			yylex.(*lexer).Assignment = Assignment{yyDollar[2].name, names, &expression{yyDollar[5].f}}
 		}
 	case 3:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:49
+//line expressions.y:52
 		{
 			yylex.(*lexer).Cycle = yyDollar[2].cycle
 		}
 	case 4:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:50
+//line expressions.y:53
 		{
 			yylex.(*lexer).Loop = yyDollar[2].loop
 		}
 	case 5:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:51
+//line expressions.y:54
 		{
 			yylex.(*lexer).When = When{yyDollar[2].exprs}
 		}
 	case 6:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line expressions.y:54
+//line expressions.y:57
 		{
 			yyVAL.cycle = yyDollar[2].cyclefn(yyDollar[1].s)
 		}
 	case 7:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:57
+//line expressions.y:60
 		{
 			h, t := yyDollar[2].s, yyDollar[3].ss
 			yyVAL.cyclefn = func(g string) Cycle { return Cycle{g, append([]string{h}, t...)} }
 		}
 	case 8:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line expressions.y:61
+//line expressions.y:64
 		{
 			vals := yyDollar[1].ss
 			yyVAL.cyclefn = func(h string) Cycle { return Cycle{Values: append([]string{h}, vals...)} }
 		}
 	case 9:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line expressions.y:68
+//line expressions.y:71
 		{
 			yyVAL.ss = []string{}
 		}
 	case 10:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:69
+//line expressions.y:72
 		{
 			yyVAL.ss = append([]string{yyDollar[2].s}, yyDollar[3].ss...)
 		}
 	case 11:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line expressions.y:76
+		{
+			yyVAL.ss = []string{}
+		}
+	case 12:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line expressions.y:77
+		{
+			yyVAL.ss = append([]string{yyDollar[2].name}, yyDollar[3].ss...)
+		}
+	case 13:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line expressions.y:72
+//line expressions.y:80
 		{
 			yyVAL.exprs = append([]Expression{&expression{yyDollar[1].f}}, yyDollar[2].exprs...)
 		}
-	case 12:
+	case 14:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line expressions.y:74
+//line expressions.y:82
 		{
 			yyVAL.exprs = []Expression{}
 		}
-	case 13:
+	case 15:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:75
+//line expressions.y:83
 		{
 			yyVAL.exprs = append([]Expression{&expression{yyDollar[2].f}}, yyDollar[3].exprs...)
 		}
-	case 14:
+	case 16:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line expressions.y:84
+		{
+			yyVAL.exprs = append([]Expression{&expression{yyDollar[2].f}}, yyDollar[3].exprs...)
+		}
+	case 17:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line expressions.y:78
+//line expressions.y:87
 		{
 			s, ok := yyDollar[1].val.(string)
 			if !ok {
@@ -627,22 +659,22 @@ yydefault:
 			}
 			yyVAL.s = s
 		}
-	case 15:
+	case 18:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line expressions.y:86
+//line expressions.y:95
 		{
 			name, expr, mods := yyDollar[1].name, yyDollar[3].f, yyDollar[4].loopmods
 			yyVAL.loop = Loop{name, &expression{expr}, mods}
 		}
-	case 16:
+	case 19:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line expressions.y:92
+//line expressions.y:101
 		{
 			yyVAL.loopmods = loopModifiers{}
 		}
-	case 17:
+	case 20:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line expressions.y:93
+//line expressions.y:102
 		{
 			switch yyDollar[2].name {
 			case "reversed":
@@ -652,9 +684,9 @@ yydefault:
 			}
 			yyVAL.loopmods = yyDollar[1].loopmods
 		}
-	case 18:
+	case 21:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:102
+//line expressions.y:111
 		{
 			switch yyDollar[2].name {
 			case "cols":
@@ -668,71 +700,109 @@ yydefault:
 			}
 			yyVAL.loopmods = yyDollar[1].loopmods
 		}
-	case 19:
+	case 22:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line expressions.y:124
+		{
+			// the scanner only produces CONTINUE after "offset:"
+			yyDollar[1].loopmods.OffsetContinue = true
+			yyVAL.loopmods = yyDollar[1].loopmods
+		}
+	case 23:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line expressions.y:118
+//line expressions.y:132
 		{
 			val := yyDollar[1].val
 			yyVAL.f = func(Context) values.Value { return values.ValueOf(val) }
+			// literal is the source text of a literal, for the path of an index expression
+			yyVAL.path, yyVAL.literal = "", literalText(val)
 		}
-	case 20:
+	case 24:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line expressions.y:119
+//line expressions.y:138
 		{
-			name := yyDollar[1].name
-			yyVAL.f = func(ctx Context) values.Value { return values.ValueOf(ctx.Get(name)) }
+			yyVAL.f = makeVariableExpr(yyDollar[1].name)
+			yyVAL.path, yyVAL.literal = yyDollar[1].name, ""
 		}
-	case 21:
+	case 25:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line expressions.y:120
+//line expressions.y:139
 		{
-			yyVAL.f = makeObjectPropertyExpr(yyDollar[1].f, yyDollar[2].name)
+			// path is the path of a variable or property chain, such as page.title or
+			// items[0].title; it is empty for other expressions.
+			path := yyDollar[2].name
+			if yyDollar[1].path != "" {
+				path = yyDollar[1].path + "." + yyDollar[2].name
+			}
+			yyVAL.f = makeObjectPropertyExpr(yyDollar[1].f, yyDollar[2].name, path)
+			yyVAL.path, yyVAL.literal = path, ""
 		}
-	case 22:
+	case 26:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line expressions.y:121
+//line expressions.y:149
 		{
-			yyVAL.f = makeIndexExpr(yyDollar[1].f, yyDollar[3].f)
+			path := indexPath(yyDollar[1].path, yyDollar[3].path, yyDollar[3].literal)
+			yyVAL.f = makeIndexExpr(yyDollar[1].f, yyDollar[3].f, path)
+			yyVAL.path, yyVAL.literal = path, ""
 		}
-	case 23:
+	case 27:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line expressions.y:122
+//line expressions.y:154
 		{
 			yyVAL.f = makeRangeExpr(yyDollar[2].f, yyDollar[4].f)
+			yyVAL.path, yyVAL.literal = "", ""
 		}
-	case 24:
+	case 28:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:123
+//line expressions.y:155
 		{
 			yyVAL.f = yyDollar[2].f
+			yyVAL.path, yyVAL.literal = "", ""
 		}
-	case 26:
+	case 30:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:128
+//line expressions.y:160
 		{
-			yyVAL.f = makeFilter(yyDollar[1].f, yyDollar[3].name, nil)
+			yyVAL.f = makeFilter(yyDollar[1].f, yyDollar[3].name, filterParams{})
 		}
-	case 27:
+	case 31:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line expressions.y:129
+//line expressions.y:161
 		{
 			yyVAL.f = makeFilter(yyDollar[1].f, yyDollar[3].name, yyDollar[4].filter_params)
 		}
-	case 28:
+	case 32:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line expressions.y:133
+//line expressions.y:165
+		{
+			yyVAL.filter_params = filterParams{positional: []valueFn{yyDollar[1].f}}
+		}
+	case 33:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line expressions.y:166
 		{
-			yyVAL.filter_params = []valueFn{yyDollar[1].f}
+			yyVAL.filter_params = filterParams{keyword: []keywordArg{{yyDollar[1].name, yyDollar[2].f}}}
 		}
-	case 29:
+	case 34:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:135
+//line expressions.y:167
 		{
-			yyVAL.filter_params = append(yyDollar[1].filter_params, yyDollar[3].f)
+			if len(yyDollar[1].filter_params.keyword) > 0 {
+				panic(SyntaxError("positional filter argument follows keyword argument"))
+			}
+			yyDollar[1].filter_params.positional = append(yyDollar[1].filter_params.positional, yyDollar[3].f)
+			yyVAL.filter_params = yyDollar[1].filter_params
 		}
-	case 31:
+	case 35:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line expressions.y:174
+		{
+			yyDollar[1].filter_params.keyword = append(yyDollar[1].filter_params.keyword, keywordArg{yyDollar[3].name, yyDollar[4].f})
+			yyVAL.filter_params = yyDollar[1].filter_params
+		}
+	case 37:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:139
+//line expressions.y:181
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
@@ -740,9 +810,9 @@ yydefault:
 				return values.ValueOf(a.Equal(b))
 			}
 		}
-	case 32:
+	case 38:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:146
+//line expressions.y:188
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
@@ -750,9 +820,9 @@ yydefault:
 				return values.ValueOf(!a.Equal(b))
 			}
 		}
-	case 33:
+	case 39:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:153
+//line expressions.y:195
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
@@ -760,9 +830,9 @@ yydefault:
 				return values.ValueOf(b.Less(a))
 			}
 		}
-	case 34:
+	case 40:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:160
+//line expressions.y:202
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
@@ -770,9 +840,9 @@ yydefault:
 				return values.ValueOf(a.Less(b))
 			}
 		}
-	case 35:
+	case 41:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:167
+//line expressions.y:209
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
@@ -780,9 +850,9 @@ yydefault:
 				return values.ValueOf(b.Less(a) || a.Equal(b))
 			}
 		}
-	case 36:
+	case 42:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:174
+//line expressions.y:216
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
@@ -790,30 +860,39 @@ yydefault:
 				return values.ValueOf(a.Less(b) || a.Equal(b))
 			}
 		}
-	case 37:
+	case 43:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:181
+//line expressions.y:223
 		{
 			yyVAL.f = makeContainsExpr(yyDollar[1].f, yyDollar[3].f)
 		}
-	case 39:
+	case 45:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:186
+//line expressions.y:231
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
 				return values.ValueOf(fa(ctx).Test() && fb(ctx).Test())
 			}
 		}
-	case 40:
+	case 46:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:192
+//line expressions.y:237
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
 				return values.ValueOf(fa(ctx).Test() || fb(ctx).Test())
 			}
 		}
+	case 48:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line expressions.y:247
+		{
+			f := yyDollar[2].f
+			yyVAL.f = func(ctx Context) values.Value {
+				return values.ValueOf(!f(ctx).Test())
+			}
+		}
 	}
 	goto yystack /* stack new state and value */
 }
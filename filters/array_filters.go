@@ -0,0 +1,416 @@
+package filters
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/osteele/liquid/expressions"
+	"github.com/osteele/liquid/values"
+)
+
+// mapFilter implements the map filter. It returns the named property of each
+// element, in order; elements that lack the property contribute nil.
+func mapFilter(a []any, property string) []any {
+	result := make([]any, len(a))
+	for i, item := range a {
+		result[i] = propertyPathValue(item, property).Interface()
+	}
+	return result
+}
+
+// whereFilter implements the where filter. With a single argument, it selects
+// the elements whose property is truthy; with two, those whose property equals
+// the target value.
+func whereFilter(a []any, property string, target ...any) (result []any) {
+	result = []any{}
+	for _, item := range a {
+		if propertyMatches(item, property, target) {
+			result = append(result, item)
+		}
+	}
+	return
+}
+
+// rejectFilter implements the reject filter. It selects the elements that
+// where would drop, including those that lack the property.
+func rejectFilter(a []any, property string, target ...any) (result []any) {
+	result = []any{}
+	for _, item := range a {
+		if !propertyMatches(item, property, target) {
+			result = append(result, item)
+		}
+	}
+	return
+}
+
+// findFilter implements the find filter. It returns the first element that
+// where would select, or nil.
+func findFilter(a []any, property string, target ...any) any {
+	for _, item := range a {
+		if propertyMatches(item, property, target) {
+			return item
+		}
+	}
+	return nil
+}
+
+// findIndexFilter implements the find_index filter. It returns the index of
+// the first element that where would select, or nil.
+func findIndexFilter(a []any, property string, target ...any) any {
+	for i, item := range a {
+		if propertyMatches(item, property, target) {
+			return i
+		}
+	}
+	return nil
+}
+
+// hasFilter implements the has filter. It reports whether where would select
+// any element.
+func hasFilter(a []any, property string, target ...any) bool {
+	return findIndexFilter(a, property, target...) != nil
+}
+
+// propertyMatches reports whether item's property is truthy, if target is
+// empty, or else equal to target[0].
+func propertyMatches(item any, property string, target []any) bool {
+	value := propertyPathValue(item, property)
+	if len(target) == 0 {
+		return value.Test()
+	}
+	return value.Interface() != nil && values.Equal(value.Interface(), target[0])
+}
+
+// whereExpFilter selects the elements for which expr, with the element bound
+// to name, is truthy.
+func whereExpFilter(a []any, name string, expr expressions.Closure) ([]any, error) {
+	result := []any{}
+	for _, item := range a {
+		value, err := expr.Bind(name, item).Evaluate()
+		if err != nil {
+			return nil, err
+		}
+		if values.ValueOf(value).Test() {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// firstFilter implements the first filter. Without a count, it returns the first
+// element, or nil if the array is empty. With a count, it returns an array of up
+// to that many elements from the start of the array.
+func firstFilter(a []any, count func(int) int) any {
+	n := count(-1)
+	if n < 0 {
+		if len(a) == 0 {
+			return nil
+		}
+		return a[0]
+	}
+	return a[:min(n, len(a))]
+}
+
+// lastFilter implements the last filter. It is like first, but takes elements
+// from the end of the array.
+func lastFilter(a []any, count func(int) int) any {
+	n := count(-1)
+	if n < 0 {
+		if len(a) == 0 {
+			return nil
+		}
+		return a[len(a)-1]
+	}
+	return a[len(a)-min(n, len(a)):]
+}
+
+// compactFilter implements the compact filter. It drops nil elements or, given
+// a property name, the elements whose property is nil.
+func compactFilter(a []any, property func(string) string) []any {
+	result := []any{}
+	key := property("")
+	for _, item := range a {
+		value := item
+		if key != "" && item != nil {
+			value = propertyPathValue(item, key).Interface()
+		}
+		if value != nil {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// uniqFilter implements the uniq filter. It keeps the first of each set of
+// elements, or of elements with property values, that compare Equal.
+func uniqFilter(a []any, property func(string) string) []any {
+	var (
+		result  = []any{}
+		keys    []any
+		strKeys = map[string]bool{}
+	)
+	key := property("")
+	seen := func(k any) bool {
+		if s, ok := k.(string); ok {
+			if strKeys[s] {
+				return true
+			}
+			strKeys[s] = true
+			return false
+		}
+		// the O(n^2) case:
+		for _, other := range keys {
+			if eqItems(k, other) {
+				return true
+			}
+		}
+		keys = append(keys, k)
+		return false
+	}
+	for _, item := range a {
+		k := item
+		if key != "" {
+			k = propertyPathValue(item, key).Interface()
+		}
+		if !seen(k) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// flattenFilter implements the flatten filter. It replaces elements that are
+// arrays by their elements, recursively or, given a depth, to that many levels.
+func flattenFilter(a []any, depth func(int) int) []any {
+	return flatten([]any{}, a, depth(-1))
+}
+
+// flatten appends the elements of a to result, flattening them to depth levels.
+// A negative depth has no limit.
+func flatten(result []any, a []any, depth int) []any {
+	for _, item := range a {
+		if depth == 0 || item == nil || !isArrayKind(reflect.TypeOf(item).Kind()) {
+			result = append(result, item)
+			continue
+		}
+		rv := reflect.ValueOf(item)
+		elems := make([]any, rv.Len())
+		for i := range elems {
+			elems[i] = rv.Index(i).Interface()
+		}
+		result = flatten(result, elems, depth-1)
+	}
+	return result
+}
+
+// intersectFilter implements the intersect filter. It returns the elements of a
+// that are Equal to an element of b, in the order of a, without duplicates.
+func intersectFilter(a, b []any) []any {
+	result := []any{}
+	for _, item := range a {
+		if containsItem(b, item) && !containsItem(result, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// intersectsFilter implements the intersects filter. It returns true if a and
+// b have an element in common.
+func intersectsFilter(a, b []any) bool {
+	for _, item := range a {
+		if containsItem(b, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// differenceFilter implements the difference filter. It returns the elements of
+// a, including duplicates, that aren't Equal to an element of b.
+func differenceFilter(a, b []any) []any {
+	result := []any{}
+	for _, item := range a {
+		if !containsItem(b, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// containsItem returns true if an element of a is eqItems to item.
+func containsItem(a []any, item any) bool {
+	for _, other := range a {
+		if eqItems(item, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// eqItems is values.Equal, extended to values such as maps that Go can't compare with ==.
+func eqItems(a, b any) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ra, rb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case ra.Type().Comparable() && rb.Type().Comparable():
+		return values.Equal(a, b)
+	case isArrayKind(ra.Kind()) && isArrayKind(rb.Kind()):
+		if ra.Len() != rb.Len() {
+			return false
+		}
+		for i := range ra.Len() {
+			if !eqItems(ra.Index(i).Interface(), rb.Index(i).Interface()) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+func isArrayKind(k reflect.Kind) bool {
+	return k == reflect.Array || k == reflect.Slice
+}
+
+// concatFilter implements the concat filter. It returns a new array with the
+// elements of a followed by those of b, which must be array-like.
+func concatFilter(a []any, b any) ([]any, error) {
+	if _, ok := b.(values.Range); !ok && (b == nil || !isArrayKind(reflect.TypeOf(b).Kind())) {
+		return nil, fmt.Errorf("concat requires an array argument; got %T", b)
+	}
+	elems, err := values.Convert(b, reflect.TypeOf(a))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]any, 0, len(a)+len(elems.([]any)))
+	return append(append(result, a...), elems.([]any)...), nil
+}
+
+// groupByFilter implements the group_by filter. It groups the elements by the
+// value of the named property, in order of each value's first appearance.
+func groupByFilter(a []any, property string) ([]any, error) {
+	return groupItems(a, func(item any) (any, error) {
+		return propertyPathValue(item, property).Interface(), nil
+	})
+}
+
+// groupByExpFilter implements the group_by_exp filter. It groups the elements
+// by the value of expr, evaluated with name bound to each element.
+func groupByExpFilter(a []any, name string, expr expressions.Closure) ([]any, error) {
+	return groupItems(a, func(item any) (any, error) {
+		return expr.Bind(name, item).Evaluate()
+	})
+}
+
+// groupItems returns a slice of {"name", "items"} maps, one per distinct key.
+func groupItems(a []any, keyFn func(any) (any, error)) ([]any, error) {
+	groups := []any{}
+	for _, item := range a {
+		key, err := keyFn(item)
+		if err != nil {
+			return nil, err
+		}
+		var group map[string]any
+		for _, g := range groups {
+			if g := g.(map[string]any); values.Equal(g["name"], key) {
+				group = g
+				break
+			}
+		}
+		if group == nil {
+			group = map[string]any{"name": key, "items": []any{}}
+			groups = append(groups, group)
+		}
+		group["items"] = append(group["items"].([]any), item)
+	}
+	return groups, nil
+}
+
+// sumFilter implements the sum filter. It returns an int if every addend is
+// an integer, and a float otherwise. Non-numeric elements are skipped.
+func sumFilter(a []any, property func(string) string) any {
+	var (
+		intSum   int64
+		floatSum float64
+		isFloat  bool
+	)
+	key := property("")
+	for _, item := range a {
+		if key != "" {
+			item = propertyPathValue(item, key).Interface()
+		}
+		switch n := toNumber(item).(type) {
+		case int64:
+			intSum += n
+		case float64:
+			floatSum += n
+			isFloat = true
+		}
+	}
+	if isFloat {
+		return float64(intSum) + floatSum
+	}
+	return intSum
+}
+
+var (
+	int64Type   = reflect.TypeOf(int64(0))
+	float64Type = reflect.TypeOf(float64(0))
+)
+
+// toNumber returns value as an int64 or float64, or nil if it isn't numeric.
+// Strings are parsed as numbers.
+func toNumber(value any) any {
+	switch value.(type) {
+	case nil, bool:
+		return nil
+	}
+	v := values.ValueOf(value)
+	if n, ok := v.Int64(); ok {
+		return n
+	}
+	if f, ok := v.Float64(); ok {
+		return f
+	}
+	if n, err := values.Convert(value, int64Type); err == nil {
+		return n
+	}
+	if f, err := values.Convert(value, float64Type); err == nil {
+		return f
+	}
+	return nil
+}
+
+// propertyPathValue returns the value of a (possibly dotted) property path,
+// such as "author.name", of obj. Missing properties evaluate to nil.
+func propertyPathValue(obj any, path string) values.Value {
+	value := values.ValueOf(obj)
+	for _, name := range strings.Split(path, ".") {
+		value = value.PropertyValue(values.ValueOf(name))
+	}
+	return value
+}
+
+// digFilter returns the value at a path of map keys, struct properties, and
+// array indices, such as "a", "b", 0, "c". Integers index arrays; other keys
+// are properties. It returns nil as soon as a step is missing.
+func digFilter(obj any, keys ...any) any {
+	value := values.ValueOf(obj)
+	for _, key := range keys {
+		if value.Interface() == nil {
+			return nil
+		}
+		k := values.ValueOf(key)
+		if _, ok := k.Int64(); ok {
+			value = value.IndexValue(k)
+		} else {
+			value = value.PropertyValue(k)
+		}
+	}
+	return value.Interface()
+}
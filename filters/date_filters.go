@@ -0,0 +1,85 @@
+package filters
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/osteele/liquid/values"
+	"github.com/osteele/tuesday"
+)
+
+// A DateConfig configures the date filter.
+type DateConfig struct {
+	// Location is the time zone that times are displayed in, and that strings
+	// that lack a time zone are read in. If it is nil, times are displayed in
+	// their own time zone, and strings are read in time.Local.
+	Location *time.Location
+	// Layouts are time.Parse layouts that are tried on strings, before the
+	// built-in layouts of values.ParseDate.
+	Layouts []string
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// AddDateFilters defines the date filter, with the configuration that config
+// returns. The filter calls config each time it is applied, so that a new
+// configuration applies to templates that have already been parsed.
+//
+// The filter takes an optional format, and an optional IANA time zone name
+// such as "Europe/Paris", which overrides c.Location. The format uses the
+// directives of Ruby's Time#strftime, including flags such as the - in %-d;
+// as in Ruby, an unrecognized directive is output as is.
+func AddDateFilters(fd FilterDictionary, config func() *DateConfig) {
+	fd.AddFilter("date", func(value any, format func(string) string, zone func(string) string) (string, error) {
+		c := config()
+		t, err := c.toTime(value)
+		if err != nil {
+			return "", err
+		}
+		if c.Location != nil {
+			t = t.In(c.Location)
+		}
+		if name := zone(""); name != "" {
+			loc, err := loadLocation(name)
+			if err != nil {
+				return "", fmt.Errorf("unknown time zone %q", name)
+			}
+			t = t.In(loc)
+		}
+		return tuesday.Strftime(format("%a, %b %d, %y"), t)
+	})
+}
+
+// locations caches the time zones that loadLocation has loaded.
+var locations sync.Map
+
+// loadLocation is time.LoadLocation, with a cache, so that the time zone
+// database is read only once for each name.
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+func (c *DateConfig) toTime(value any) (time.Time, error) {
+	if s, ok := value.(string); ok {
+		loc := c.Location
+		if loc == nil {
+			loc = time.Local
+		}
+		return values.ParseDateInLocation(s, loc, c.Layouts...)
+	}
+	t, err := values.Convert(value, timeType)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.(time.Time), nil
+}
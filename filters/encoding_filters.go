@@ -0,0 +1,63 @@
+package filters
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"net/url"
+)
+
+// urlEncodeFilter form-encodes s. Like Ruby's CGI.escape, which Shopify uses,
+// it encodes a space as "+" and leaves only letters, digits, and "-._~" as is.
+func urlEncodeFilter(s string) string {
+	return url.QueryEscape(s)
+}
+
+// urlDecodeFilter decodes a form-encoded string, in which "+" is a space.
+// A malformed percent-escape is an error.
+func urlDecodeFilter(s string) (string, error) {
+	return url.QueryUnescape(s)
+}
+
+func base64EncodeFilter(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func base64DecodeFilter(s string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func base64URLSafeEncodeFilter(s string) string {
+	return base64.URLEncoding.EncodeToString([]byte(s))
+}
+
+func base64URLSafeDecodeFilter(s string) (string, error) {
+	b, err := base64.URLEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// hashFilter returns a filter that returns the lowercase hex digest of its input.
+func hashFilter(newHash func() hash.Hash) func(string) string {
+	return func(s string) string {
+		h := newHash()
+		h.Write([]byte(s)) // nolint: errcheck
+		return hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+// hmacFilter returns a filter that returns the lowercase hex HMAC of its input.
+func hmacFilter(newHash func() hash.Hash) func(string, string) string {
+	return func(s, key string) string {
+		h := hmac.New(newHash, []byte(key))
+		h.Write([]byte(s)) // nolint: errcheck
+		return hex.EncodeToString(h.Sum(nil))
+	}
+}
@@ -0,0 +1,69 @@
+package filters
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/osteele/liquid/values"
+)
+
+// jsonFilter serializes a value to JSON. Map keys are sorted, so that the output
+// is deterministic. If indent is positive, the output is indented by that many
+// spaces per level. A cyclic value is an error.
+func jsonFilter(value any, indent func(int) int) (string, error) {
+	value = values.ToLiquid(value)
+	if v, ok := value.(values.Value); ok {
+		value = v.Interface()
+	}
+	var (
+		b   []byte
+		err error
+	)
+	if n := indent(0); n > 0 {
+		b, err = json.MarshalIndent(value, "", strings.Repeat(" ", n))
+	} else {
+		b, err = json.Marshal(value)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// parseJSONFilter implements the parse_json filter. It is the inverse of json.
+// Objects are maps, and arrays are slices. Integers are int64, so that they
+// behave as integer literals do, and other numbers are float64.
+func parseJSONFilter(s string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("invalid JSON: unexpected data after the value")
+	}
+	return convertJSONNumbers(value), nil
+}
+
+// convertJSONNumbers replaces the json.Numbers in value by int64 or float64.
+func convertJSONNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i, item := range v {
+			v[i] = convertJSONNumbers(item)
+		}
+	case map[string]any:
+		for k, item := range v {
+			v[k] = convertJSONNumbers(item)
+		}
+	}
+	return value
+}
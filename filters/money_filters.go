@@ -0,0 +1,91 @@
+package filters
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A MoneyFormat configures the money and money_with_currency filters.
+type MoneyFormat struct {
+	// Symbol precedes the amount, e.g. "$".
+	Symbol string
+	// Currency follows the output of money_with_currency, e.g. "USD".
+	Currency string
+	// DecimalSeparator and ThousandsSeparator are e.g. "." and "," in the US,
+	// and "," and "." in much of Europe.
+	DecimalSeparator   string
+	ThousandsSeparator string
+	// Precision is the number of digits after the decimal separator.
+	Precision int
+	// Cents causes integer inputs to be read as hundredths of the currency unit,
+	// as in Shopify, so that 1999 formats as $19.99. Floats are always amounts.
+	Cents bool
+}
+
+// DefaultMoneyFormat formats US dollars, from integer cents or float amounts.
+var DefaultMoneyFormat = MoneyFormat{
+	Symbol:             "$",
+	Currency:           "USD",
+	DecimalSeparator:   ".",
+	ThousandsSeparator: ",",
+	Precision:          2,
+	Cents:              true,
+}
+
+// AddMoneyFilters defines the money and money_with_currency filters, with format f.
+func AddMoneyFilters(fd FilterDictionary, f MoneyFormat) {
+	fd.AddFilter("money", func(value any) (string, error) {
+		return f.format(value, false)
+	})
+	fd.AddFilter("money_with_currency", func(value any) (string, error) {
+		return f.format(value, true)
+	})
+}
+
+func (f MoneyFormat) format(value any, withCurrency bool) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	var amount float64
+	switch n := toNumber(value).(type) {
+	case int64:
+		amount = float64(n)
+		if f.Cents {
+			amount /= 100
+		}
+	case float64:
+		amount = n
+	default:
+		return "", fmt.Errorf("money requires a number; got %v", value)
+	}
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	// round half away from zero, in units of the last digit
+	units := int64(math.Round(amount * math.Pow10(f.Precision)))
+	if units == 0 {
+		sign = ""
+	}
+	digits := strconv.FormatInt(units, 10)
+	if len(digits) <= f.Precision {
+		digits = strings.Repeat("0", f.Precision-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-f.Precision], digits[len(digits)-f.Precision:]
+	var b strings.Builder
+	b.WriteString(sign)
+	b.WriteString(f.Symbol)
+	b.WriteString(groupDigits(whole, f.ThousandsSeparator))
+	if frac != "" {
+		b.WriteString(f.DecimalSeparator)
+		b.WriteString(frac)
+	}
+	if withCurrency && f.Currency != "" {
+		b.WriteString(" ")
+		b.WriteString(f.Currency)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,151 @@
+package filters
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// dividedByFilter divides a by b. As in Shopify, if both are integers, this is
+// integer division, which rounds down; otherwise it is float division.
+func dividedByFilter(a, b any) (any, error) {
+	x, y := toNumber(a), toNumber(b)
+	if x == nil {
+		return nil, fmt.Errorf("not a number: %v", a)
+	}
+	if y == nil {
+		return nil, fmt.Errorf("invalid divisor: '%v'", b)
+	}
+	if toFloat(y) == 0 {
+		return nil, errDivisionByZero
+	}
+	xi, xInt := x.(int64)
+	yi, yInt := y.(int64)
+	if xInt && yInt {
+		q := xi / yi
+		if (xi%yi != 0) && ((xi < 0) != (yi < 0)) {
+			q--
+		}
+		return q, nil
+	}
+	return toFloat(x) / toFloat(y), nil
+}
+
+// roundingFilter returns a round, ceil, or floor filter, according to fn.
+// The filter rounds to an optional number of decimal places. If this is zero,
+// the default, or negative, as in 1234 | round: -2, the result is an int, unless
+// it is too large for an int.
+func roundingFilter(fn func(float64) float64) func(float64, func(int) int) any {
+	return func(n float64, places func(int) int) any {
+		p := places(0)
+		r := shiftDecimal(fn(shiftDecimal(n, p)), -p)
+		if p <= 0 && math.MinInt <= r && r < -math.MinInt {
+			return int(r)
+		}
+		return r
+	}
+}
+
+// shiftDecimal returns n × 10^places. It shifts the decimal exponent of n
+// instead of multiplying, so that 0.29 shifted by 2 is exactly 29 and not
+// 28.999999999999996.
+func shiftDecimal(n float64, places int) float64 {
+	if places == 0 || n == 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return n
+	}
+	mantissa, exp, _ := strings.Cut(strconv.FormatFloat(n, 'e', -1, 64), "e")
+	e, _ := strconv.Atoi(exp)
+	// this returns ±Inf or 0 if the result is out of range
+	f, _ := strconv.ParseFloat(mantissa+"e"+strconv.Itoa(e+places), 64)
+	return f
+}
+
+// atLeastFilter returns the larger of a and b. If both are integers, so is the result.
+func atLeastFilter(a, b any) (any, error) {
+	return clamp(a, b, true)
+}
+
+// atMostFilter returns the smaller of a and b. If both are integers, so is the result.
+func atMostFilter(a, b any) (any, error) {
+	return clamp(a, b, false)
+}
+
+// clamp returns the larger of a and b if max is true, and otherwise the smaller.
+func clamp(a, b any, max bool) (any, error) {
+	x, y := toNumber(a), toNumber(b)
+	if x == nil {
+		return nil, fmt.Errorf("not a number: %v", a)
+	}
+	if y == nil {
+		return nil, fmt.Errorf("not a number: %v", b)
+	}
+	xi, xInt := x.(int64)
+	yi, yInt := y.(int64)
+	if xInt && yInt {
+		if (xi < yi) == max {
+			return yi, nil
+		}
+		return xi, nil
+	}
+	xf, yf := toFloat(x), toFloat(y)
+	if (xf < yf) == max {
+		return yf, nil
+	}
+	return xf, nil
+}
+
+// toFloat converts the result of toNumber to a float64.
+func toFloat(n any) float64 {
+	if i, ok := n.(int64); ok {
+		return float64(i)
+	}
+	return n.(float64)
+}
+
+// pluralizeFilter returns singular if count is 1, and plural otherwise. If
+// plural is omitted, it is singular followed by "s".
+func pluralizeFilter(count int, singular string, plural func(string) string) string {
+	if count == 1 {
+		return singular
+	}
+	return plural(singular + "s")
+}
+
+// numberWithDelimiterFilter implements the number_with_delimiter filter, after
+// the Rails helper. It formats a number with delimiter, by default ",", between
+// groups of three digits, and separator, by default ".", before the decimal
+// digits. A float keeps the digits of its shortest representation.
+func numberWithDelimiterFilter(value any, delimiter, separator func(string) string) (string, error) {
+	var digits string
+	switch n := toNumber(value).(type) {
+	case int64:
+		digits = strconv.FormatInt(n, 10)
+	case float64:
+		digits = strconv.FormatFloat(n, 'f', -1, 64)
+	default:
+		return "", fmt.Errorf("number_with_delimiter requires a number; got %v", value)
+	}
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	whole, frac, found := strings.Cut(digits, ".")
+	s := sign + groupDigits(whole, delimiter(","))
+	if found {
+		s += separator(".") + frac
+	}
+	return s, nil
+}
+
+// groupDigits inserts sep between groups of three digits, from the right.
+func groupDigits(digits, sep string) string {
+	var b strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -2,54 +2,70 @@ package filters
 
 import (
 	"fmt"
-	"reflect"
 	"sort"
 	"strings"
 
+	"github.com/osteele/liquid/expressions"
 	"github.com/osteele/liquid/values"
 )
 
+// sortFilter implements the sort filter. With a property, the sort is stable,
+// and elements without the property sort last, as in Shopify.
 func sortFilter(array []any, key any) []any {
 	result := make([]any, len(array))
 	copy(result, array)
 	if key == nil {
 		values.Sort(result)
 	} else {
-		values.SortByProperty(result, fmt.Sprint(key), true)
+		values.SortByProperty(result, fmt.Sprint(key), false)
 	}
 	return result
 }
 
-func sortNaturalFilter(array []any, key any) any {
+// sortByExpFilter implements the sort_by_exp filter. It sorts the elements by
+// the value of expr with name bound to each element, as sort sorts by a
+// property. If reverse is true, the order is descending; the sort is still
+// stable, and elements whose value is nil still sort last.
+func sortByExpFilter(array []any, name string, expr expressions.Closure, reverse func(bool) bool) ([]any, error) {
 	result := make([]any, len(array))
 	copy(result, array)
-	switch {
-	case reflect.ValueOf(array).Len() == 0:
-	case key != nil:
-		sort.Sort(keySortable{result, func(m any) string {
-			rv := reflect.ValueOf(m)
-			if rv.Kind() != reflect.Map {
-				return ""
-			}
-			ev := rv.MapIndex(reflect.ValueOf(key))
-			if ev.CanInterface() {
-				if s, ok := ev.Interface().(string); ok {
-					return strings.ToLower(s)
-				}
-			}
-			return ""
-		}})
-	case reflect.TypeOf(array[0]).Kind() == reflect.String:
-		sort.Sort(keySortable{result, func(s any) string {
-			return strings.ToUpper(s.(string))
-		}})
+	keys := make([]any, len(result))
+	for i, item := range result {
+		key, err := expr.Bind(name, item).Evaluate()
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	values.SortByKeys(result, keys, false, reverse(false))
+	return result, nil
+}
+
+// sortNaturalFilter implements the sort_natural filter. Strings compare
+// case-insensitively; other values compare as in sort. The sort is stable, and
+// elements without the property sort last.
+func sortNaturalFilter(array []any, key any) []any {
+	result := make([]any, len(array))
+	copy(result, array)
+	keyFn := func(item any) any { return item }
+	if key != nil {
+		property := fmt.Sprint(key)
+		keyFn = func(item any) any {
+			return propertyPathValue(item, property).Interface()
+		}
 	}
+	keys := make([]any, len(result))
+	for i, item := range result {
+		keys[i] = keyFn(item)
+	}
+	sort.Stable(keySortable{result, keys})
 	return result
 }
 
+// keySortable sorts a slice by precomputed keys, in natural order.
 type keySortable struct {
 	slice []any
-	keyFn func(any) string
+	keys  []any
 }
 
 // Len is part of sort.Interface.
@@ -59,13 +75,28 @@ func (s keySortable) Len() int {
 
 // Swap is part of sort.Interface.
 func (s keySortable) Swap(i, j int) {
-	a := s.slice
-	a[i], a[j] = a[j], a[i]
+	s.slice[i], s.slice[j] = s.slice[j], s.slice[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
 }
 
 // Less is part of sort.Interface.
 func (s keySortable) Less(i, j int) bool {
-	k, sl := s.keyFn, s.slice
-	a, b := k(sl[i]), k(sl[j])
-	return a < b
+	return naturalLess(s.keys[i], s.keys[j])
+}
+
+// naturalLess compares strings case-insensitively, and other values with values.Less.
+// nil sorts after everything else.
+func naturalLess(a, b any) bool {
+	switch {
+	case a == nil:
+		return false
+	case b == nil:
+		return true
+	}
+	if sa, ok := a.(string); ok {
+		if sb, ok := b.(string); ok {
+			return strings.ToLower(sa) < strings.ToLower(sb)
+		}
+	}
+	return values.Less(a, b)
 }
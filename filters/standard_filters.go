@@ -2,25 +2,43 @@
 package filters
 
 import (
-	"encoding/json"
+	"crypto/md5"  //nolint: gosec
+	"crypto/sha1" //nolint: gosec
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"html"
 	"math"
-	"net/url"
 	"reflect"
 	"regexp"
+	"slices"
 	"strings"
-	"time"
 	"unicode"
-	"unicode/utf8"
 
+	"github.com/osteele/liquid/expressions"
 	"github.com/osteele/liquid/values"
-	"github.com/osteele/tuesday"
 )
 
 var errDivisionByZero = errors.New("division by zero")
 
+// escapeOnceRegexp matches the characters that escape_once escapes, and the
+// character and entity references that it leaves alone.
+var escapeOnceRegexp = regexp.MustCompile(`[<>"']|&(?:[a-zA-Z][a-zA-Z0-9]*|#[0-9]+|#[xX][0-9a-fA-F]+);|&`)
+
+// These follow Shopify's strip_html, which removes script and style elements
+// and comments along with their content, and then the remaining tags.
+var (
+	stripHTMLBlocksRegexp = regexp.MustCompile(`(?is)<script.*?</script>|<!--.*?-->|<style.*?</style>`)
+	stripHTMLTagsRegexp   = regexp.MustCompile(`(?s)<.*?>`)
+)
+
+// lineBreakRegexp matches the line breaks that newline_to_br replaces, with or
+// without a carriage return.
+var lineBreakRegexp = regexp.MustCompile(`\r?\n`)
+
+// newlineReplacer removes carriage returns and newlines.
+var newlineReplacer = strings.NewReplacer("\r", "", "\n", "")
+
 // A FilterDictionary holds filters.
 type FilterDictionary interface {
 	AddFilter(string, any)
@@ -29,71 +47,59 @@ type FilterDictionary interface {
 // AddStandardFilters defines the standard Liquid filters.
 func AddStandardFilters(fd FilterDictionary) { //nolint: gocyclo
 	// value filters
-	fd.AddFilter("default", func(value, defaultValue any) any {
-		if value == nil || value == false || values.IsEmpty(value) {
+	fd.AddFilter("default", func(value, defaultValue any, kwargs expressions.KeywordArgs) any {
+		if value == false {
+			if values.ValueOf(kwargs["allow_false"]).Test() {
+				return value
+			}
+			return defaultValue
+		}
+		if value == nil || values.IsEmpty(value) {
 			value = defaultValue
 		}
 		return value
 	})
-	fd.AddFilter("json", func(a any) any {
-		result, _ := json.Marshal(a)
-		return result
-	})
+	fd.AddFilter("dig", digFilter)
+	fd.AddFilter("json", jsonFilter)
+	fd.AddFilter("parse_json", parseJSONFilter)
 
 	// array filters
-	fd.AddFilter("compact", func(a []any) (result []any) {
-		for _, item := range a {
-			if item != nil {
-				result = append(result, item)
-			}
-		}
-		return
-	})
-	fd.AddFilter("concat", func(a, b []any) (result []any) {
-		result = make([]any, 0, len(a)+len(b))
-		return append(append(result, a...), b...)
-	})
+	fd.AddFilter("array_to_sentence_string", arrayToSentenceStringFilter)
+	fd.AddFilter("compact", compactFilter)
+	fd.AddFilter("concat", concatFilter)
+	fd.AddFilter("difference", differenceFilter)
+	fd.AddFilter("flatten", flattenFilter)
+	fd.AddFilter("intersect", intersectFilter)
+	fd.AddFilter("intersects", intersectsFilter)
 	fd.AddFilter("join", joinFilter)
-	fd.AddFilter("map", func(a []any, key string) (result []any) {
-		keyValue := values.ValueOf(key)
-		for _, obj := range a {
-			value := values.ValueOf(obj)
-			result = append(result, value.PropertyValue(keyValue).Interface())
-		}
-		return result
-	})
+	fd.AddFilter("map", mapFilter)
 	fd.AddFilter("reverse", reverseFilter)
 	fd.AddFilter("sort", sortFilter)
+	fd.AddFilter("sort_by_exp", sortByExpFilter)
 	// https://shopify.github.io/liquid/ does not demonstrate first and last as filters,
 	// but https://help.shopify.com/themes/liquid/filters/array-filters does
-	fd.AddFilter("first", func(a []any) any {
-		if len(a) == 0 {
-			return nil
-		}
-		return a[0]
-	})
-	fd.AddFilter("last", func(a []any) any {
-		if len(a) == 0 {
-			return nil
-		}
-		return a[len(a)-1]
-	})
+	fd.AddFilter("first", firstFilter)
+	fd.AddFilter("last", lastFilter)
 	fd.AddFilter("uniq", uniqFilter)
+	fd.AddFilter("where", whereFilter)
+	fd.AddFilter("where_exp", whereExpFilter)
+	fd.AddFilter("reject", rejectFilter)
+	fd.AddFilter("find", findFilter)
+	fd.AddFilter("find_index", findIndexFilter)
+	fd.AddFilter("has", hasFilter)
+	fd.AddFilter("group_by", groupByFilter)
+	fd.AddFilter("group_by_exp", groupByExpFilter)
+	fd.AddFilter("sum", sumFilter)
 
 	// date filters
-	fd.AddFilter("date", func(t time.Time, format func(string) string) (string, error) {
-		f := format("%a, %b %d, %y")
-		return tuesday.Strftime(f, t)
-	})
+	AddDateFilters(fd, func() *DateConfig { return &DateConfig{} })
 
 	// number filters
 	fd.AddFilter("abs", math.Abs)
-	fd.AddFilter("ceil", func(a float64) int {
-		return int(math.Ceil(a))
-	})
-	fd.AddFilter("floor", func(a float64) int {
-		return int(math.Floor(a))
-	})
+	fd.AddFilter("at_least", atLeastFilter)
+	fd.AddFilter("at_most", atMostFilter)
+	fd.AddFilter("ceil", roundingFilter(math.Ceil))
+	fd.AddFilter("floor", roundingFilter(math.Floor))
 	fd.AddFilter("modulo", math.Mod)
 	fd.AddFilter("minus", func(a, b float64) float64 {
 		return a - b
@@ -104,49 +110,10 @@ func AddStandardFilters(fd FilterDictionary) { //nolint: gocyclo
 	fd.AddFilter("times", func(a, b float64) float64 {
 		return a * b
 	})
-	fd.AddFilter("divided_by", func(a float64, b any) (any, error) {
-		divInt := func(a, b int64) (int64, error) {
-			if b == 0 {
-				return 0, errDivisionByZero
-			}
-			return a / b, nil
-		}
-		divFloat := func(a, b float64) (float64, error) {
-			if b == 0 {
-				return 0, errDivisionByZero
-			}
-			return a / b, nil
-		}
-		switch q := b.(type) {
-		case int:
-			return divInt(int64(a), int64(q))
-		case int8:
-			return divInt(int64(a), int64(q))
-		case int16:
-			return divInt(int64(a), int64(q))
-		case int32:
-			return divInt(int64(a), int64(q))
-		case int64:
-			return divInt(int64(a), q)
-		case uint8:
-			return divInt(int64(a), int64(q))
-		case uint16:
-			return divInt(int64(a), int64(q))
-		case uint32:
-			return divInt(int64(a), int64(q))
-		case float32:
-			return divFloat(a, float64(q))
-		case float64:
-			return divFloat(a, q)
-		default:
-			return nil, fmt.Errorf("invalid divisor: '%v'", b)
-		}
-	})
-	fd.AddFilter("round", func(n float64, places func(int) int) float64 {
-		pl := places(0)
-		exp := math.Pow10(pl)
-		return math.Floor(n*exp+0.5) / exp
-	})
+	fd.AddFilter("divided_by", dividedByFilter)
+	fd.AddFilter("round", roundingFilter(math.Round))
+	fd.AddFilter("number_with_delimiter", numberWithDelimiterFilter)
+	fd.AddFilter("pluralize", pluralizeFilter)
 
 	// sequence filters
 	fd.AddFilter("size", values.Length)
@@ -156,30 +123,25 @@ func AddStandardFilters(fd FilterDictionary) { //nolint: gocyclo
 		return s + suffix
 	})
 
-	fd.AddFilter("capitalize", func(s, suffix string) string {
-		if len(s) == 0 {
-			return s
-		}
-
-		r, size := utf8.DecodeRuneInString(s)
-		switch {
-		case r == utf8.RuneError:
-			return s
-		case !unicode.IsLetter(r):
-			return s
-		}
-
-		return string(unicode.ToUpper(r)) + s[size:]
-	})
+	fd.AddFilter("begins_with", strings.HasPrefix)
+	fd.AddFilter("capitalize", capitalizeFilter)
 	fd.AddFilter("downcase", func(s, suffix string) string {
 		return strings.ToLower(s)
 	})
+	fd.AddFilter("ends_with", strings.HasSuffix)
 	fd.AddFilter("escape", html.EscapeString)
-	fd.AddFilter("escape_once", func(s, suffix string) string {
-		return html.EscapeString(html.UnescapeString(s))
+	fd.AddFilter("escape_once", func(s string) string {
+		return escapeOnceRegexp.ReplaceAllStringFunc(s, func(m string) string {
+			if len(m) > 1 {
+				return m
+			}
+			return html.EscapeString(m)
+		})
 	})
+	fd.AddFilter("handle", handleizeFilter)
+	fd.AddFilter("handleize", handleizeFilter)
 	fd.AddFilter("newline_to_br", func(s string) string {
-		return strings.ReplaceAll(s, "\n", "<br />")
+		return lineBreakRegexp.ReplaceAllString(s, "<br />\n")
 	})
 	fd.AddFilter("prepend", func(s, prefix string) string {
 		return prefix + s
@@ -190,36 +152,23 @@ func AddStandardFilters(fd FilterDictionary) { //nolint: gocyclo
 	fd.AddFilter("remove_first", func(s, old string) string {
 		return strings.Replace(s, old, "", 1)
 	})
+	fd.AddFilter("remove_last", func(s, old string) string {
+		return replaceLast(s, old, "")
+	})
 	fd.AddFilter("replace", strings.ReplaceAll)
 	fd.AddFilter("replace_first", func(s, old, n string) string {
 		return strings.Replace(s, old, n, 1)
 	})
+	fd.AddFilter("replace_last", replaceLast)
 	fd.AddFilter("sort_natural", sortNaturalFilter)
-	fd.AddFilter("slice", func(s string, start int, length func(int) int) string {
-		if len(s) == 0 {
-			return ""
-		}
-		ss := []rune(s)
-		n := length(1)
-		if start < 0 {
-			start = len(ss) + start
-		}
-		if start < 0 {
-			return ""
-		}
-		end := start + n
-		if end > len(ss) {
-			end = len(ss)
-		}
-		return string(ss[start:end])
-	})
+	fd.AddFilter("slice", sliceFilter)
 	fd.AddFilter("split", splitFilter)
 	fd.AddFilter("strip_html", func(s string) string {
-		// TODO this probably isn't sufficient
-		return regexp.MustCompile(`<.*?>`).ReplaceAllString(s, "")
+		s = stripHTMLBlocksRegexp.ReplaceAllString(s, "")
+		return stripHTMLTagsRegexp.ReplaceAllString(s, "")
 	})
 	fd.AddFilter("strip_newlines", func(s string) string {
-		return strings.ReplaceAll(s, "\n", "")
+		return newlineReplacer.Replace(s)
 	})
 	fd.AddFilter("strip", strings.TrimSpace)
 	fd.AddFilter("lstrip", func(s string) string {
@@ -228,6 +177,7 @@ func AddStandardFilters(fd FilterDictionary) { //nolint: gocyclo
 	fd.AddFilter("rstrip", func(s string) string {
 		return strings.TrimRightFunc(s, unicode.IsSpace)
 	})
+	fd.AddFilter("titleize", titleizeFilter)
 	fd.AddFilter("truncate", func(s string, length func(int) int, ellipsis func(string) string) string {
 		n := length(50)
 		el := ellipsis("...")
@@ -235,57 +185,158 @@ func AddStandardFilters(fd FilterDictionary) { //nolint: gocyclo
 		re := regexp.MustCompile(fmt.Sprintf(`^(.{%d})..{%d,}`, n-len(el), len(el)))
 		return re.ReplaceAllString(s, `$1`+el)
 	})
-	fd.AddFilter("truncatewords", func(s string, length func(int) int, ellipsis func(string) string) string {
-		el := ellipsis("...")
-		n := length(15)
-		re := regexp.MustCompile(fmt.Sprintf(`^(?:\s*\S+){%d}`, n))
-		m := re.FindString(s)
-		if m == "" {
-			return s
-		}
-		return m + el
-	})
+	fd.AddFilter("truncatewords", truncateWordsFilter)
 	fd.AddFilter("upcase", func(s, suffix string) string {
 		return strings.ToUpper(s)
 	})
-	fd.AddFilter("url_encode", url.QueryEscape)
-	fd.AddFilter("url_decode", url.QueryUnescape)
+	fd.AddFilter("url_encode", urlEncodeFilter)
+	fd.AddFilter("url_decode", urlDecodeFilter)
+
+	AddMoneyFilters(fd, DefaultMoneyFormat)
+
+	// encoding filters
+	fd.AddFilter("base64_encode", base64EncodeFilter)
+	fd.AddFilter("base64_decode", base64DecodeFilter)
+	fd.AddFilter("base64_url_safe_encode", base64URLSafeEncodeFilter)
+	fd.AddFilter("base64_url_safe_decode", base64URLSafeDecodeFilter)
+	fd.AddFilter("md5", hashFilter(md5.New))
+	fd.AddFilter("sha1", hashFilter(sha1.New))
+	fd.AddFilter("sha256", hashFilter(sha256.New))
+	fd.AddFilter("hmac_sha1", hmacFilter(sha1.New))
+	fd.AddFilter("hmac_sha256", hmacFilter(sha256.New))
 
 	// debugging filters
-	// inspect is from Jekyll
+	// inspect shows the Go syntax of a value, including the Go types of the
+	// value and its elements
 	fd.AddFilter("inspect", func(value any) string {
-		s, err := json.Marshal(value)
-		if err != nil {
-			return fmt.Sprintf("%#v", value)
-		}
-		return string(s)
+		return fmt.Sprintf("%#v", values.ValueOf(value).Interface())
 	})
 	fd.AddFilter("type", func(value any) string {
 		return fmt.Sprintf("%T", value)
 	})
 }
 
-func joinFilter(a []any, sep func(string) string) any {
-	ss := make([]string, 0, len(a))
-	s := sep(" ")
-	for _, v := range a {
-		if v != nil {
-			ss = append(ss, fmt.Sprint(v))
-		}
+// joinFilter implements the join filter. Each element is formatted as an object
+// would render it, so that floats don't have Go's exponent notation and nil
+// elements are empty.
+func joinFilter(a []any, sep func(string) string) string {
+	ss := formatElements(a)
+	return strings.Join(ss, sep(" "))
+}
+
+// formatElements formats each element of a as an object would render it.
+func formatElements(a []any) []string {
+	ss := make([]string, len(a))
+	for i, v := range a {
+		ss[i] = values.Format(v)
 	}
-	return strings.Join(ss, s)
+	return ss
 }
 
-func reverseFilter(a []any) any {
+// arrayToSentenceStringFilter implements Jekyll's array_to_sentence_string
+// filter: ["a", "b", "c"] is "a, b, and c", and ["a", "b"] is "a and b". The
+// elements are formatted as by join.
+func arrayToSentenceStringFilter(a []any, connector func(string) string) string {
+	ss := formatElements(a)
+	conj := connector("and")
+	switch len(ss) {
+	case 0:
+		return ""
+	case 1:
+		return ss[0]
+	case 2:
+		return ss[0] + " " + conj + " " + ss[1]
+	default:
+		return strings.Join(ss[:len(ss)-1], ", ") + ", " + conj + " " + ss[len(ss)-1]
+	}
+}
+
+// reverseFilter implements the reverse filter. It returns a reversed copy of an
+// array or, given a string, a string with its runes in reverse order. nil is
+// returned unchanged, so that it renders as empty.
+func reverseFilter(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if s, ok := v.(string); ok {
+		rs := []rune(s)
+		slices.Reverse(rs)
+		return string(rs), nil
+	}
+	elems, err := values.Convert(v, reflect.TypeOf([]any{}))
+	if err != nil {
+		return nil, err
+	}
+	a := elems.([]any)
 	result := make([]any, len(a))
 	for i, x := range a {
 		result[len(result)-1-i] = x
 	}
-	return result
+	return result, nil
 }
 
 var wsre = regexp.MustCompile(`[[:space:]]+`)
 
+// replaceLast replaces the last instance of old in s by n. As with Ruby's
+// String#rindex, an empty old matches at the end of s.
+func replaceLast(s, old, n string) string {
+	i := strings.LastIndex(s, old)
+	if i < 0 {
+		return s
+	}
+	return s[:i] + n + s[i+len(old):]
+}
+
+// truncateWordsFilter truncates s to its first n words, joined by single
+// spaces, followed by the ellipsis. As in Shopify, a string that has at most
+// n words is returned unchanged, and n is at least 1.
+func truncateWordsFilter(s string, length func(int) int, ellipsis func(string) string) string {
+	n := length(15)
+	if n <= 0 {
+		n = 1
+	}
+	words := strings.Fields(s)
+	if len(words) <= n {
+		return s
+	}
+	return strings.Join(words[:n], " ") + ellipsis("...")
+}
+
+// sliceFilter implements the slice filter. It returns length elements, if v
+// is an array, or else runes of v's string form, beginning at start. A negative
+// start counts from the end.
+func sliceFilter(v any, start int, length func(int) int) (any, error) {
+	n := length(1)
+	if _, ok := v.(values.Range); ok || (v != nil && isArrayKind(reflect.TypeOf(v).Kind())) {
+		elems, err := values.Convert(v, reflect.TypeOf([]any{}))
+		if err != nil {
+			return nil, err
+		}
+		a := elems.([]any)
+		i, j := sliceBounds(len(a), start, n)
+		return a[i:j], nil
+	}
+	str, err := values.Convert(v, reflect.TypeOf(""))
+	if err != nil {
+		return nil, err
+	}
+	rs := []rune(str.(string))
+	i, j := sliceBounds(len(rs), start, n)
+	return string(rs[i:j]), nil
+}
+
+// sliceBounds returns the bounds of the slice of length n at start, clipped
+// to a sequence of length size.
+func sliceBounds(size, start, n int) (int, int) {
+	if start < 0 {
+		start += size
+	}
+	if start < 0 || start > size || n <= 0 {
+		return 0, 0
+	}
+	return start, min(start+n, size)
+}
+
 func splitFilter(s, sep string) any {
 	result := strings.Split(s, sep)
 	if sep == " " {
@@ -298,36 +349,3 @@ func splitFilter(s, sep string) any {
 	}
 	return result
 }
-
-func uniqFilter(a []any) (result []any) {
-	seenMap := map[any]bool{}
-	seen := func(item any) bool {
-		if k := reflect.TypeOf(item).Kind(); k < reflect.Array || k == reflect.Ptr || k == reflect.UnsafePointer {
-			if seenMap[item] {
-				return true
-			}
-			seenMap[item] = true
-			return false
-		}
-		// the O(n^2) case:
-		for _, other := range result {
-			if eqItems(item, other) {
-				return true
-			}
-		}
-		return false
-	}
-	for _, item := range a {
-		if !seen(item) {
-			result = append(result, item)
-		}
-	}
-	return
-}
-
-func eqItems(a, b any) bool {
-	if reflect.TypeOf(a).Comparable() && reflect.TypeOf(b).Comparable() {
-		return a == b
-	}
-	return reflect.DeepEqual(a, b)
-}
@@ -2,6 +2,7 @@ package filters
 
 import (
 	"fmt"
+	"strconv"
 	"testing"
 	"time"
 
@@ -25,32 +26,136 @@ var filterTests = []struct {
 	{`empty_map_slice | default: 2.99`, 2.99},
 	{`true | default: 2.99`, true},
 	{`"true" | default: 2.99`, "true"},
+	{`false | default: "n/a", allow_false: true`, false},
+	{`false | default: "n/a", allow_false: false`, "n/a"},
+	{`true | default: "n/a", allow_false: true`, true},
+	{`nil | default: "n/a", allow_false: true`, "n/a"},
+	{`"" | default: "n/a", allow_false: true`, "n/a"},
+	{`empty_array | default: "n/a", allow_false: true`, "n/a"},
+	{`0 | default: "n/a", allow_false: true`, 0},
 	{`4.99 | default: 2.99`, 4.99},
 	{`fruits | default: 2.99 | join`, "apples oranges peaches plums"},
 	{`"string" | json`, "\"string\""},
 	{`true | json`, "true"},
 	{`1 | json`, "1"},
+	{`nil | json`, "null"},
+	{`"<b>" | json`, `"\u003cb\u003e"`},
+	{`json_object | parse_json`, map[string]any{"name": "Ann", "age": int64(42), "score": 9.5, "tags": []any{"a", "b"}, "address": map[string]any{"city": "Paris"}, "none": nil}},
+	{`'[1, 2.5, "x", true, null, [3]]' | parse_json`, []any{int64(1), 2.5, "x", true, nil, []any{int64(3)}}},
+	{`" 7 " | parse_json`, int64(7)},
+	{`json_object | parse_json | json`, `{"address":{"city":"Paris"},"age":42,"name":"Ann","none":null,"score":9.5,"tags":["a","b"]}`},
+	{`"[1, 2, 3]" | parse_json | sum`, int64(6)},
+	{`json_map | json`, `{"a":[1,2],"b":{"c":"d","e":null},"z":true}`},
+	{`fruits | json`, `["apples","oranges","peaches","plums"]`},
+	{`json_struct | json`, `{"Title":"Shirt","price":10.5}`},
+	{`json_map | json: 2`, "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {\n    \"c\": \"d\",\n    \"e\": null\n  },\n  \"z\": true\n}"},
+	{`fruits | json: 0`, `["apples","oranges","peaches","plums"]`},
 
 	// array filters
-	{`pages | map: 'category' | join`, "business celebrities lifestyle sports technology"},
+	{`pages | map: 'category' | join`, "business celebrities  lifestyle sports  technology"},
 	{`pages | map: 'category' | compact | join`, "business celebrities lifestyle sports technology"},
 	{`"mangos bananas persimmons" | split: " " | concat: fruits | join: ", "`, "mangos, bananas, persimmons, apples, oranges, peaches, plums"},
+	{`dup_ints | concat: fruits | join: ", "`, "1, 2, 1, 3, apples, oranges, peaches, plums"},
+	{`fruits | concat: empty_array | size`, 4},
+	{`empty_array | concat: map_slice_2 | join`, "b a"},
+	{`empty_array | concat: (1..3) | join`, "1 2 3"},
 	{`"John, Paul, George, Ringo" | split: ", " | join: " and "`, "John and Paul and George and Ringo"},
 	{`",John, Paul, George, Ringo" | split: ", " | join: " and "`, ",John and Paul and George and Ringo"},
 	{`"John, Paul, George, Ringo," | split: ", " | join: " and "`, "John and Paul and George and Ringo,"},
+	{`post_tags | intersects: selected_tags`, true},
+	{`post_tags | intersects: other_tags`, false},
+	{`post_tags | intersects: empty_array`, false},
+	{`post_tags | intersect: selected_tags`, []any{"go", "liquid"}},
+	{`selected_tags | intersect: post_tags`, []any{"liquid", "go"}},
+	{`post_tags | intersect: other_tags`, []any{}},
+	{`dup_ints | intersect: dup_ints`, []any{1, 2, 3}},
+	{`summands | intersect: int_floats`, []any{1, int64(4)}},
+	{`int_floats | intersects: dup_ints`, true},
+	{`post_tags | difference: selected_tags`, []any{"templates"}},
+	{`post_tags | difference: other_tags`, []any{"go", "templates", "liquid", "go"}},
+	{`post_tags | difference: empty_array`, []any{"go", "templates", "liquid", "go"}},
+	{`dup_ints | difference: int_floats`, []any{2, 3}},
+	{`summands | difference: int_floats`, []any{2.5, uint8(3), "x", nil, true}},
+	{`empty_array | difference: post_tags`, []any{}},
+	{`post_tags | difference: post_tags`, []any{}},
+	{`nested | flatten`, []any{1, 2, 3, 4, 5, "six", nil, 7, 8}},
+	{`nested | flatten: 1`, []any{1, 2, []any{3, []int{4, 5}}, "six", nil, []int{7}, []int{8}}},
+	{`nested | flatten: 2`, []any{1, 2, 3, []int{4, 5}, "six", nil, 7, 8}},
+	{`nested | flatten: 0`, []any{1, []any{2, []any{3, []int{4, 5}}}, "six", nil, []string{}, [][]int{{7}, {8}}}},
+	{`fruits | flatten | join`, "apples oranges peaches plums"},
+	{`empty_array | flatten`, []any{}},
+	{`mixed_join | join`, "1 a true "},
+	{`empty_array | array_to_sentence_string`, ""},
+	{`"a" | split: " " | array_to_sentence_string`, "a"},
+	{`"a b" | split: " " | array_to_sentence_string`, "a and b"},
+	{`fruits | array_to_sentence_string`, "apples, oranges, peaches, and plums"},
+	{`fruits | array_to_sentence_string: "or"`, "apples, oranges, peaches, or plums"},
+	{`"a b" | split: " " | array_to_sentence_string: "&"`, "a & b"},
+	{`float_join | array_to_sentence_string`, "2.5, 1000000, and -0.125"},
+	{`mixed_join | join: ", "`, "1, a, true, "},
+	{`float_join | join: "/"`, "2.5/1000000/-0.125"},
+	{`empty_array | join: ", "`, ""},
 	{`animals | sort | join: ", "`, "Sally Snake, giraffe, octopus, zebra"},
-	{`sort_prop | sort: "weight" | inspect`, `[{"weight":null},{"weight":1},{"weight":3},{"weight":5}]`},
+	{`sort_prop | sort: "weight" | json`, `[{"weight":1},{"weight":3},{"weight":5},{"weight":null}]`},
+	{`pages | sort: "category" | map: "name" | join: ", "`, "page 1, page 2, page 4, page 5, page 7, page 3, page 6"},
+	{`sort_partial | sort: "priority" | map: "name" | join`, "c a d f b e"},
+	{`sort_mixed | sort: "key" | map: "key" | join`, "10 2 a b"},
+	{`sort_mixed_values | sort | join`, "0 10 1a 2 3 b"},
+	{`sort_mixed_values | reverse | sort | join`, "0 10 1a 2 3 b"},
+	{`sort_mixed | reverse | sort: "key" | map: "key" | join`, "10 2 a b"},
+	{`order_items | sort_by_exp: "x", "x.price | times: x.quantity" | map: "name" | join`, "pen mug book"},
+	{`order_items | sort_by_exp: "x", "x.price | times: x.quantity", true | map: "name" | join`, "book mug pen"},
+	{`order_items | sort_by_exp: "x", "x.name | size" | map: "name" | join`, "pen mug book"},
+	{`products | sort_by_exp: "p", "p.title | downcase" | map: "title" | join`, "Hat Pan Shirt Spatula"},
+	{`products | sort_by_exp: "p", "p.type" | map: "title" | join`, "Shirt Hat Spatula Pan"},
+	{`products | sort_by_exp: "p", "p.type", true | map: "title" | join`, "Spatula Shirt Hat Pan"},
+	{`products | reverse | sort_by_exp: "p", "p.type", true | map: "title" | join`, "Spatula Hat Shirt Pan"},
+	{`sort_mixed | sort_by_exp: "m", "m.key" | map: "key" | join`, "10 2 a b"},
+	{`sort_mixed | reverse | sort_by_exp: "m", "m.key" | map: "key" | join`, "10 2 a b"},
+	{`sort_mixed | sort_by_exp: "m", "m.key", true | map: "key" | join`, "b a 2 10"},
+	{`sort_partial | sort_by_exp: "x", "x.priority", true | map: "name" | join`, "f a d c b e"},
+	{`empty_array | sort_by_exp: "x", "x"`, []any{}},
+	{`natural_structs | sort: "title" | map: "id" | join`, "2 4 3 1"},
 	{`fruits | reverse | join: ", "`, "plums, peaches, oranges, apples"},
+	{`nil | reverse`, nil},
+	{`undefined_variable | reverse`, nil},
+	{`"abc" | reverse`, "cba"},
+	{`"héllo, 世界" | reverse`, "界世 ,olléh"},
+	{`"" | reverse`, ""},
+	{`(1..3) | reverse | join`, "3 2 1"},
+	{`empty_array | reverse`, []any{}},
 	{`fruits | first`, "apples"},
 	{`fruits | last`, "plums"},
 	{`empty_array | first`, nil},
 	{`empty_array | last`, nil},
 	{`empty_array | last`, nil},
+	{`fruits | first: 2`, []any{"apples", "oranges"}},
+	{`fruits | last: 2`, []any{"peaches", "plums"}},
+	{`fruits | first: 1`, []any{"apples"}},
+	{`fruits | last: 0`, []any{}},
+	{`fruits | first: 10 | join: ", "`, "apples, oranges, peaches, plums"},
+	{`fruits | last: 10 | join: ", "`, "apples, oranges, peaches, plums"},
+	{`empty_array | first: 2`, []any{}},
+	{`empty_array | last: 2`, []any{}},
 	{`dup_ints | uniq | join`, "1 2 3"},
 	{`dup_strings | uniq | join`, "one two three"},
 	{`dup_maps | uniq | map: "name" | join`, "m1 m2 m3"},
+	{`dup_numbers | uniq | json`, `[1,2,3]`},
+	{`dup_nils | uniq | json`, `[null,"a"]`},
+	{`dup_slices | uniq | json`, `[[1,2],[3]]`},
+	{`pages | uniq: "category" | map: "name" | join: ", "`, "page 1, page 2, page 3, page 4, page 5, page 7"},
+	{`dup_structs | uniq: "id" | map: "name" | join`, "a b"},
+	{`empty_array | uniq | json`, `[]`},
+	{`pages | compact: "category" | map: "name" | join: ", "`, "page 1, page 2, page 4, page 5, page 7"},
+	{`map_slice_has_nil | compact | size`, 2},
+	{`empty_array | compact | json`, `[]`},
 	{`mixed_case_array | sort_natural | join`, "a B c"},
 	{`mixed_case_hash_values | sort_natural: 'key' | map: 'key' | join`, "a B c"},
+	{`natural_strings | sort_natural | join: ", "`, "Apple, banana, BANANA, Banana, cherry"},
+	{`dup_numbers | sort_natural | json`, `[1,1,2,2,3,3]`},
+	{`natural_structs | sort_natural: "title" | map: "id" | join`, "4 2 3 1"},
+	{`pages | sort_natural: "category" | map: "name" | join: ", "`, "page 1, page 2, page 4, page 5, page 7, page 3, page 6"},
+	{`empty_array | sort_natural | json`, `[]`},
 
 	{`map_slice_has_nil | compact | join`, `a b`},
 	{`map_slice_2 | first`, `b`},
@@ -63,6 +168,72 @@ var filterTests = []struct {
 	{`map_slice_dup | uniq | join`, `a b`},
 
 	{`struct_slice | map: "str" | join`, `a b c`},
+	{`struct_slice | map: "missing" | size`, 3},
+	{`struct_slice | map: "missing" | first`, nil},
+	{`pages | map: 'category' | size`, 7},
+	{`pages | map: 'category' | json`, `["business","celebrities",null,"lifestyle","sports",null,"technology"]`},
+	{`products | map: "author.name" | json`, `["Ann",null,null,null]`},
+	{`empty_array | map: "title" | json`, `[]`},
+
+	{`products | where: "available" | map: "title" | join`, `Shirt Hat`},
+	{`products | where: "type", "kitchen" | map: "title" | join`, `Spatula`},
+	{`products | where: "available", true | map: "title" | join`, `Shirt Hat`},
+	{`products | where: "available", false | map: "title" | join`, `Spatula`},
+	{`products | where: "author.name", "Ann" | map: "title" | join`, `Shirt`},
+	{`products | where: "type", "none" | size`, 0},
+	{`product_structs | where: "available" | map: "title" | join`, `Shirt Hat`},
+	{`product_structs | where: "type", "kitchen" | map: "title" | join`, `Spatula`},
+	{`empty_array | where: "available" | size`, 0},
+	{`products | reject: "available" | map: "title" | join`, `Spatula Pan`},
+	{`products | reject: "type", "kitchen" | map: "title" | join`, `Shirt Hat Pan`},
+	{`products | reject: "available", true | map: "title" | join`, `Spatula Pan`},
+	{`products | reject: "available", false | map: "title" | join`, `Shirt Hat Pan`},
+	{`products | reject: "author.name", "Ann" | map: "title" | join`, `Spatula Hat Pan`},
+	{`products | reject: "type", "none" | size`, 4},
+	{`product_structs | reject: "available" | map: "title" | join`, `Spatula`},
+	{`product_structs | reject: "type", "kitchen" | map: "title" | join`, `Shirt Hat`},
+	{`empty_array | reject: "available" | size`, 0},
+
+	{`products | group_by: "type" | map: "name" | json`, `["clothing","kitchen",null]`},
+	{`products | group_by: "type" | json`, `[{"items":[{"author":{"name":"Ann"},"available":true,"title":"Shirt","type":"clothing"},{"available":true,"title":"Hat","type":"clothing"}],"name":"clothing"},{"items":[{"available":false,"title":"Spatula","type":"kitchen"}],"name":"kitchen"},{"items":[{"title":"Pan"}],"name":null}]`},
+	{`product_structs | group_by: "available" | map: "name" | join`, `true false`},
+	{`empty_array | group_by: "type" | json`, `[]`},
+	{`products | group_by_exp: "p", "p.title | size" | map: "name" | join`, `5 7 3`},
+	{`product_structs | group_by_exp: "p", "p.type == 'clothing'" | map: "name" | join`, `true false`},
+	{`empty_array | group_by_exp: "p", "p.type" | json`, `[]`},
+	{`products | where_exp: "p", "p.type == 'clothing'" | map: "title" | join`, `Shirt Hat`},
+	{`products | where_exp: "p", "p.available" | map: "title" | join`, `Shirt Hat`},
+	{`products | where_exp: "p", "p.author.name == 'Ann'" | map: "title" | join`, `Shirt`},
+	{`product_structs | where_exp: "item", "item.type != 'clothing'" | map: "title" | join`, `Spatula`},
+	{`products | where_exp: "p", "p.type == 'garden'" | json`, `[]`},
+	{`empty_array | where_exp: "p", "p.type" | json`, `[]`},
+	{`products | find: "type", "kitchen" | json`, `{"available":false,"title":"Spatula","type":"kitchen"}`},
+	{`products | find: "type", "garden"`, nil},
+	{`products | find: "available" | json`, `{"author":{"name":"Ann"},"available":true,"title":"Shirt","type":"clothing"}`},
+	{`product_structs | find_index: "type", "kitchen"`, 1},
+	{`empty_array | find: "available"`, nil},
+	{`products | find_index: "type", "kitchen"`, 1},
+	{`products | find_index: "type", "garden"`, nil},
+	{`products | find_index: "title", "Pan"`, 3},
+	{`product_structs | find_index: "available"`, 0},
+	{`empty_array | find_index: "available"`, nil},
+	{`products | has: "type", "kitchen"`, true},
+	{`products | has: "type", "garden"`, false},
+	{`products | has: "available"`, true},
+	{`products | has: "author.name", "Bob"`, false},
+	{`product_structs | has: "type", "clothing"`, true},
+	{`empty_array | has: "available"`, false},
+
+	{`dup_ints | sum`, 7},
+	{`dup_ints | sum | type`, `int64`},
+	{`summands | sum`, 10.5},
+	{`summands | sum | type`, `float64`},
+	{`string_summands | sum`, 8},
+	{`string_summands | sum | type`, `int64`},
+	{`line_items | sum: "price"`, 12.5},
+	{`line_items | sum: "quantity"`, 6},
+	{`empty_array | sum`, 0},
+	{`fruits | sum`, 0},
 
 	// date filters
 	{`article.published_at | date`, "Fri, Jul 17, 15"},
@@ -81,6 +252,11 @@ var filterTests = []struct {
 	{`"2017-07-09" | date: "%-d/%-m"`, "9/7"},
 	{`1730040524 | date: "%b %d, %y"`, "Oct 27, 24"},
 	{`"1730040524" | date: "%b %d, %y"`, "Oct 27, 24"},
+	{`article.published_at | date: "%Y-%m-%d %H:%M:%S %z", "Asia/Tokyo"`, "2015-07-18 00:04:05 +0900"},
+	{`article.published_at | date: "%A %B %j %I:%M %p", "America/Los_Angeles"`, "Friday July 198 08:04 AM"},
+	{`"2017-02-08T09:00:00Z" | date: "%H:%M %z", "Europe/Paris"`, "10:00 +0100"},
+	{`1730040524 | date: "%H:%M", "UTC"`, "14:48"},
+	{`article.published_at | date: "%H:%M", ""`, "15:04"},
 
 	// sequence (array or string) filters
 	{`"Ground control to Major Tom." | size`, 28},
@@ -89,22 +265,77 @@ var filterTests = []struct {
 	{`"Straße" | size`, 6},
 
 	// string filters
+	{`"Hello World" | handleize`, "hello-world"},
+	{`"100% M & Ms!!!" | handleize`, "100-m-ms"},
+	{`"  --Leading and trailing--  " | handleize`, "leading-and-trailing"},
+	{`"one, two;  three...four" | handleize`, "one-two-three-four"},
+	{`"Men's Shirts" | handle`, "mens-shirts"},
+	{`"Crème Brûlée à la carte" | handleize`, "creme-brulee-a-la-carte"},
+	{`"東京 Tokyo" | handleize`, "tokyo"},
+	{`"!!!" | handleize`, ""},
+	{`2024 | handleize`, "2024"},
 	{`"Take my protein pills and put my helmet on" | replace: "my", "your"`, "Take your protein pills and put your helmet on"},
 	{`"Take my protein pills and put my helmet on" | replace_first: "my", "your"`, "Take your protein pills and put my helmet on"},
+	{`"Take my protein pills and put my helmet on" | replace_last: "my", "your"`, "Take my protein pills and put your helmet on"},
+	{`"Take my protein pills" | replace_first: "your", "my"`, "Take my protein pills"},
+	{`"Take my protein pills" | replace_last: "your", "my"`, "Take my protein pills"},
+	{`"aaaa" | replace_first: "aa", "b"`, "baa"},
+	{`"aaa" | replace_first: "aa", "b"`, "ba"},
+	{`"aaa" | replace_last: "aa", "b"`, "ab"},
+	{`"abc" | replace_first: "", "x"`, "xabc"},
+	{`"abc" | replace_last: "", "x"`, "abcx"},
+	{`1001 | replace_last: 1, 2`, "1002"},
 	{`"/my/fancy/url" | append: ".html"`, "/my/fancy/url.html"},
 	{`"website.com" | append: "/index.html"`, "website.com/index.html"},
+
+	{`"Dr. Jones" | begins_with: "Dr"`, true},
+	{`"Dr. Jones" | begins_with: "dr"`, false},
+	{`"Dr. Jones" | begins_with: ""`, true},
+	{`"Jones" | ends_with: "nes"`, true},
+	{`"Jones" | ends_with: "Jo"`, false},
+	{`12345 | begins_with: 12`, true},
+	{`12345 | ends_with: "45"`, true},
+	{`1.5 | ends_with: ".5"`, true},
+	{`true | begins_with: "t"`, true},
+	{`nil | begins_with: "a"`, false},
+	{`nil | ends_with: ""`, true},
+	{`"abc" | begins_with: nil`, true},
 	{`"title" | capitalize`, "Title"},
 	{`"my great title" | capitalize`, "My great title"},
+	{`"my GREAT title" | capitalize`, "My great title"},
+	{`"ÉCOLE Élémentaire" | capitalize`, "École élémentaire"},
+	{`"1ST place" | capitalize`, "1st place"},
+	{`"(hello) World" | capitalize`, "(hello) world"},
 	{`"" | capitalize`, ""},
+	{`"my GREAT title" | titleize`, "My Great Title"},
+	{`"ÉCOLE élémentaire du  lac" | titleize`, "École Élémentaire Du  Lac"},
+	{`"(hello) world" | titleize`, "(Hello) World"},
+	{`"1st place, don't stop" | titleize`, "1st Place, Don't Stop"},
+	{`"hello-world" | titleize`, "Hello-World"},
+	{`"" | titleize`, ""},
 	{`"ওয়েস্টার্ন" | capitalize`, "ওয়েস্টার্ন"}, // outputs valid UTF-8
 	{`"Parker Moore" | downcase`, "parker moore"},
 	{`"Have you read 'James & the Giant Peach'?" | escape`, "Have you read &#39;James &amp; the Giant Peach&#39;?"},
 	{`"1 < 2 & 3" | escape_once`, "1 &lt; 2 &amp; 3"},
-	{`string_with_newlines | newline_to_br`, "<br />Hello<br />there<br />"},
+	{`string_with_newlines | newline_to_br`, "<br />\nHello<br />\nthere<br />\n"},
+	{`crlf_lines | newline_to_br`, "<br />\n\tone<br />\n two\tthree <br />\n"},
+	{`"no newlines" | newline_to_br`, "no newlines"},
+	{`12 | newline_to_br`, "12"},
 	{`"1 &lt; 2 &amp; 3" | escape_once`, "1 &lt; 2 &amp; 3"},
+	{`"<p>Tom &amp; Jerry & friends</p>" | escape_once`, "&lt;p&gt;Tom &amp; Jerry &amp; friends&lt;/p&gt;"},
+	{`"&copy; 2024 &#169; &#xA9; &notanentity &#; &" | escape_once`, "&copy; 2024 &#169; &#xA9; &amp;notanentity &amp;#; &amp;"},
+	{`'say "hi" &quot;there&quot;' | escape_once`, "say &#34;hi&#34; &quot;there&quot;"},
+	{`"it's" | escape_once`, "it&#39;s"},
 	{`"apples, oranges, and bananas" | prepend: "Some fruit: "`, "Some fruit: apples, oranges, and bananas"},
 	{`"I strained to see the train through the rain" | remove: "rain"`, "I sted to see the t through the "},
 	{`"I strained to see the train through the rain" | remove_first: "rain"`, "I sted to see the train through the rain"},
+	{`"I strained to see the train through the rain" | remove_last: "rain"`, "I strained to see the train through the "},
+	{`"I strained" | remove_first: "snow"`, "I strained"},
+	{`"I strained" | remove_last: "snow"`, "I strained"},
+	{`"aaa" | remove_first: "aa"`, "a"},
+	{`"aaa" | remove_last: "aa"`, "a"},
+	{`"abc" | remove_first: ""`, "abc"},
+	{`"abc" | remove_last: ""`, "abc"},
 
 	{`"Liquid" | slice: 0`, "L"},
 	{`"Liquid
@@ -116,6 +347,19 @@ Liquid" | slice: 2, 4`, "quid"},
 	{`"Liquid" | slice: -3, 2`, "ui"},
 	{`"" | slice: 1`, ""},
 	{`"Liquid" | slice: -7`, ""},
+	{`"Liquid" | slice: 6`, ""},
+	{`"Liquid" | slice: 2, -1`, ""},
+	{`"héllo wörld" | slice: 1, 4`, "éllo"},
+	{`"héllo wörld" | slice: -5, 3`, "wör"},
+	{`"héllo wörld" | slice: -1`, "d"},
+	{`slice_array | slice: 1, 2 | join`, "b c"},
+	{`slice_array | slice: -2, 2 | join`, "d e"},
+	{`slice_array | slice: -2, 10 | join`, "d e"},
+	{`slice_array | slice: 0 | join`, "a"},
+	{`slice_array | slice: 9 | size`, 0},
+	{`slice_array | slice: -9, 2 | size`, 0},
+	{`empty_array | slice: 0 | size`, 0},
+	{`(1..5) | slice: 1, 3 | join`, "2 3 4"},
 
 	{`"a/b/c" | split: '/' | join: '-'`, "a-b-c"},
 	{`"a/b/" | split: '/' | join: '-'`, "a-b"},
@@ -134,7 +378,12 @@ Liquid" | slice: 2, 4`, "quid"},
 	{"'a \t b' | split: ' ' | join: '-'", "a-b"},
 
 	{`"Have <em>you</em> read <strong>Ulysses</strong>?" | strip_html`, "Have you read Ulysses?"},
+	{`"<div><p>Have <em><a href='/u'>you</a></em></p> read it?</div>" | strip_html`, "Have you read it?"},
+	{`"a<!-- a <b>comment</b> -->b" | strip_html`, "ab"},
+	{`html_blocks | strip_html`, "Hello\n  world !"},
 	{`string_with_newlines | strip_newlines`, "Hellothere"},
+	{`crlf_lines | strip_newlines`, "\tone two\tthree "},
+	{`12 | strip_newlines`, "12"},
 
 	{`"Ground control to Major Tom." | truncate: 20`, "Ground control to..."},
 	{`"Ground control to Major Tom." | truncate: 25, ", and so on"`, "Ground control, and so on"},
@@ -148,29 +397,121 @@ Liquid" | slice: 2, 4`, "quid"},
 	{`"  Ground" | truncatewords: 3, ""`, "  Ground"},
 	{`"" | truncatewords: 3, ""`, ""},
 	{`"  " | truncatewords: 3, ""`, "  "},
+	{`"Ground control to" | truncatewords: 3`, "Ground control to"},
+	{`"Ground control to Major Tom." | truncatewords: 2, "…"`, "Ground control…"},
+	{`"Ground   control  to Major Tom." | truncatewords: 3`, "Ground control to..."},
+	{`"  Ground control to Major Tom.  " | truncatewords: 2`, "Ground control..."},
+	{`"Ground control to Major Tom." | truncatewords: 0`, "Ground..."},
+	{`"Ground control to Major Tom." | truncatewords: -2`, "Ground..."},
+	{`"Ground control to Major Tom." | truncatewords`, "Ground control to Major Tom."},
 
 	{`"Parker Moore" | upcase`, "PARKER MOORE"},
 	{`"          So much room for activities!          " | strip`, "So much room for activities!"},
 	{`"          So much room for activities!          " | lstrip`, "So much room for activities!          "},
 	{`"          So much room for activities!          " | rstrip`, "          So much room for activities!"},
+	{`crlf_lines | lstrip`, "one\r\n two\tthree \r\n"},
+	{`crlf_lines | rstrip`, "\r\n\tone\r\n two\tthree"},
+	{`crlf_lines | strip`, "one\r\n two\tthree"},
+	{`unicode_spaces | lstrip`, "x\u3000\t"},
+	{`unicode_spaces | rstrip`, "\t\u00a0\u2003x"},
 
 	{`"%27Stop%21%27+said+Fred" | url_decode`, "'Stop!' said Fred"},
 	{`"john@liquid.com" | url_encode`, "john%40liquid.com"},
+	{`"one two three" | base64_encode`, "b25lIHR3byB0aHJlZQ=="},
+	{`"b25lIHR3byB0aHJlZQ==" | base64_decode`, "one two three"},
+	{`"ök?>" | base64_encode`, "w7ZrPz4="},
+	{`"ök?>" | base64_url_safe_encode`, "w7ZrPz4="},
+	{`"<<??>>" | base64_encode`, "PDw/Pz4+"},
+	{`"<<??>>" | base64_url_safe_encode`, "PDw_Pz4-"},
+	{`"PDw_Pz4-" | base64_url_safe_decode`, "<<??>>"},
+	{`"<<??>> ök" | base64_encode | base64_decode`, "<<??>> ök"},
+	{`"<<??>> ök" | base64_url_safe_encode | base64_url_safe_decode`, "<<??>> ök"},
+	{`12 | base64_encode`, "MTI="},
+	{`"" | base64_decode`, ""},
+
+	{`1234567 | number_with_delimiter`, "1,234,567"},
+	{`123 | number_with_delimiter`, "123"},
+	{`0 | number_with_delimiter`, "0"},
+	{`-1234567 | number_with_delimiter`, "-1,234,567"},
+	{`1234567.891 | number_with_delimiter`, "1,234,567.891"},
+	{`-1234.5 | number_with_delimiter`, "-1,234.5"},
+	{`"98765" | number_with_delimiter`, "98,765"},
+	{`1234567 | number_with_delimiter: "."`, "1.234.567"},
+	{`1234567.25 | number_with_delimiter: ".", ","`, "1.234.567,25"},
+	{`1234567 | number_with_delimiter: " "`, "1 234 567"},
+	{`1999 | money`, "$19.99"},
+	{`1234567 | money_with_currency`, "$12,345.67 USD"},
+	{`100000000 | money`, "$1,000,000.00"},
+	{`-150 | money`, "-$1.50"},
+	{`0 | money`, "$0.00"},
+	{`-0.001 | money`, "$0.00"},
+	{`19.5 | money`, "$19.50"},
+	{`"250" | money`, "$2.50"},
+	{`nil | money`, ""},
+
+	{`"" | md5`, "d41d8cd98f00b204e9800998ecf8427e"},
+	{`"The quick brown fox jumps over the lazy dog" | md5`, "9e107d9d372bb6826bd81d3542a419d6"},
+	{`"abc" | sha1`, "a9993e364706816aba3e25717850c26c9cd0d89d"},
+	{`"abc" | sha256`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	{`12 | sha256`, "6b51d431df5d7f141cbececcf79edf3dd861c3b4069f0b11661a3eefacbba918"},
+	{`"The quick brown fox jumps over the lazy dog" | hmac_sha1: "key"`, "de7c9b85b8b78aa6bc8a7a36f70a90701c9db4d9"},
+	{`"The quick brown fox jumps over the lazy dog" | hmac_sha256: "key"`, "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+	{`"Liquid" | hmac_sha256: "secret"`, "91fa60ba53e4dc2bd23f3e84ab25a25d877abfb33ab5d63dbd9579b75ae837c0"},
 	{`"Tetsuro Takara" | url_encode`, "Tetsuro+Takara"},
+	{`"a+b c%d/e?f=g&h~i*j" | url_encode`, "a%2Bb+c%25d%2Fe%3Ff%3Dg%26h~i%2Aj"},
+	{`"a+b%20c" | url_decode`, "a b c"},
+	{`"a%2Bb" | url_decode`, "a+b"},
+	{`"a+b c%d/é" | url_encode | url_decode`, "a+b c%d/é"},
+	{`12.5 | url_encode`, "12.5"},
 
 	// number filters
 	{`-17 | abs`, 17.0},
 	{`4 | abs`, 4.0},
 	{`"-19.86" | abs`, 19.86},
 
+	{`4 | at_least: 5`, 5},
+	{`4 | at_least: 3`, 4},
+	{`4 | at_least: 4.5`, 4.5},
+	{`4.5 | at_least: 4`, 4.5},
+	{`4.0 | at_least: 3`, 4.0},
+	{`-7 | at_least: -10`, -7},
+	{`-7 | at_least: 0`, 0},
+	{`"8" | at_least: 5`, 8},
+	{`4 | at_most: 5`, 4},
+	{`4 | at_most: 3`, 3},
+	{`4 | at_most: 3.5`, 3.5},
+	{`-7 | at_most: -10`, -10},
+	{`-7.5 | at_most: -7`, -7.5},
+	{`"2.5" | at_most: 5`, 2.5},
+	{`4 | at_least: 5 | type`, "int64"},
+	{`4 | at_most: 5.0 | type`, "float64"},
+	{`4.0 | at_most: 5 | type`, "float64"},
+
 	{`1.2 | ceil`, 2},
 	{`2.0 | ceil`, 2},
 	{`183.357 | ceil`, 184},
 	{`"3.5" | ceil`, 4},
+	{`-1.2 | ceil`, -1},
+	{`3.14159 | ceil: 2`, 3.15},
+	{`1234.5 | ceil: -2`, 1300},
+	{`1.2 | ceil | type`, "int"},
+	{`1.2 | ceil: 1 | type`, "float64"},
+	{`0.29 | ceil: 2`, 0.29},
+	{`1.1 | ceil: 1`, 1.1},
+	{`big | ceil`, 1e20},
+	{`big | ceil: -2 | type`, "float64"},
 
 	{`1.2 | floor`, 1},
 	{`2.0 | floor`, 2},
 	{`183.357 | floor`, 183},
+	{`-1.2 | floor`, -2},
+	{`3.14159 | floor: 2`, 3.14},
+	{`1299 | floor: -2`, 1200},
+	{`1.2 | floor | type`, "int"},
+	{`0.29 | floor: 2`, 0.29},
+	{`1.15 | floor: 2`, 1.15},
+	{`-0.29 | floor: 2`, -0.29},
+	{`big | floor: -2`, 1e20},
 
 	{`4 | plus: 2`, 6.0},
 	{`183.357 | plus: 12`, 195.357},
@@ -191,14 +532,67 @@ Liquid" | slice: 2, 4`, "quid"},
 	{`5 | divided_by: 3`, 1},
 	{`20 | divided_by: 7`, 2},
 	{`20 | divided_by: 7.0`, 2.857142857142857},
+	{`5 | divided_by: 2`, 2},
+	{`5.0 | divided_by: 2`, 2.5},
+	{`5 | divided_by: 2.0`, 2.5},
+	{`-5 | divided_by: 2`, -3},
+	{`5 | divided_by: -2`, -3},
+	{`-6 | divided_by: 2`, -3},
+	{`"10" | divided_by: "4"`, 2},
+	{`5 | divided_by: 2 | type`, "int64"},
+	{`5.0 | divided_by: 2 | type`, "float64"},
 
 	{`1.2 | round`, 1.0},
 	{`2.7 | round`, 3.0},
 	{`183.357 | round: 2`, 183.36},
+	{`3.14159 | round: 2`, 3.14},
+	{`3.14159 | round: 0`, 3},
+	{`2.5 | round`, 3},
+	{`-2.5 | round`, -3},
+	{`1250 | round: -2`, 1300},
+	{`1234 | round: -1`, 1230},
+	{`"4.567" | round: 1`, 4.6},
+	{`2.7 | round | type`, "int"},
+	{`big | round`, 1e20},
+	{`big | round: -2`, 1e20},
+	{`big | round | type`, "float64"},
+	{`big | times: -1 | round: -2`, -1e20},
+	{`2.7 | round: 1 | type`, "float64"},
+	{`2.7 | round: -1 | type`, "int"},
+
+	{`0 | pluralize: "item", "items"`, "items"},
+	{`1 | pluralize: "item", "items"`, "item"},
+	{`2 | pluralize: "item", "items"`, "items"},
+	{`1.0 | pluralize: "person", "people"`, "person"},
+	{`"1" | pluralize: "person", "people"`, "person"},
+	{`3 | pluralize: "person", "people"`, "people"},
+	{`1 | pluralize: "item"`, "item"},
+	{`2 | pluralize: "item"`, "items"},
+
+	// dig
+	{`dig_data | dig: "a", "b", 0, "c"`, "deep"},
+	{`dig_data | dig: "a", "b", -1, "c"`, "last"},
+	{`dig_data | dig: "a", "b", 1`, map[string]any{"c": "last"}},
+	{`dig_data | dig: "a", "b", "size"`, 2},
+	{`dig_data | dig: "a", "b", "first", "c"`, "deep"},
+	{`dig_data | dig: "a", "missing", 0, "c"`, nil},
+	{`dig_data | dig: "a", "b", 5, "c"`, nil},
+	{`dig_data | dig: "a", "b", 0, "c", "d"`, nil},
+	{`dig_data | dig: "a", "b", "0"`, nil},
+	{`dig_data | dig: "s", "Meta", "k"`, "v"},
+	{`dig_data | dig: "s", "Items", 0`, 1},
+	{`dig_data | dig: "n", 10`, "ten"},
+	{`nil | dig: "a"`, nil},
+	{`"str" | dig: 0`, nil},
 
 	// Jekyll extensions; added here for convenient testing
 	// TODO add this just to the test environment
-	{`map | inspect`, `{"a":1}`},
+	{`map | inspect`, `map[string]interface {}{"a":1}`},
+	{`json_struct | inspect`, `struct { Title string; Price float64 "json:\"price\""; hidden int }{Title:"Shirt", Price:10.5, hidden:1}`},
+	{`nil | inspect`, `<nil>`},
+	{`undefined_variable | inspect`, `<nil>`},
+	{`"1" | inspect`, `"1"`},
+	{`sort_prop | sort: "weight" | last | inspect`, `map[string]interface {}{"weight":interface {}(nil)}`},
 	{`1 | type`, `int`},
 	{`"1" | type`, `string`},
 }
@@ -208,13 +602,48 @@ var filterErrorTests = []struct {
 	error string
 }{
 	{`20 | divided_by: 's'`, `error applying filter "divided_by" ("invalid divisor: 's'")`},
+	{`'{"a": ' | parse_json`, `error applying filter "parse_json" ("unexpected EOF")`},
+	{`"{a: 1}" | parse_json`, `error applying filter "parse_json" ("invalid character 'a' looking for beginning of object key string")`},
+	{`"[1] [2]" | parse_json`, `error applying filter "parse_json" ("invalid JSON: unexpected data after the value")`},
+	{`"abc" | number_with_delimiter`, `error applying filter "number_with_delimiter" ("number_with_delimiter requires a number; got abc")`},
 	{`20 | divided_by: 0`, `error applying filter "divided_by" ("division by zero")`},
+	{`20.5 | divided_by: 0.0`, `error applying filter "divided_by" ("division by zero")`},
+	{`"x" | divided_by: 2`, `error applying filter "divided_by" ("not a number: x")`},
+	{`"b25l!" | base64_decode`, `error applying filter "base64_decode" ("illegal base64 data at input byte 4")`},
+	{`"PDw/Pz4+" | base64_url_safe_decode`, `error applying filter "base64_url_safe_decode" ("illegal base64 data at input byte 3")`},
+	{`"100%" | url_decode`, `error applying filter "url_decode" ("invalid URL escape \"%\"")`},
+	{`"%zz" | url_decode`, `error applying filter "url_decode" ("invalid URL escape \"%zz\"")`},
+	{`article.published_at | date: "%Y", "Nowhere/Special"`, `error applying filter "date" ("unknown time zone \"Nowhere/Special\"")`},
+	{`"abc" | money`, `error applying filter "money" ("money requires a number; got abc")`},
+	{`"x" | at_least: 5`, `error applying filter "at_least" ("not a number: x")`},
+	{`5 | at_most: "y"`, `error applying filter "at_most" ("not a number: y")`},
+	{`"x" | append: "y", allow_false: true`, `error applying filter "append" ("unexpected keyword argument \"allow_false\"")`},
+	{`cyclic | json`, `error applying filter "json" ("json: unsupported value: encountered a cycle via map[string]interface {}")`},
+	{`fruits | concat: "plums"`, `error applying filter "concat" ("concat requires an array argument; got string")`},
+	{`fruits | concat: map`, `error applying filter "concat" ("concat requires an array argument; got map[string]interface {}")`},
+	{`fruits | concat: undefined`, `error applying filter "concat" ("concat requires an array argument; got <nil>")`},
+}
+
+type digStruct struct {
+	Meta  map[string]any
+	Items []int
 }
 
 var filterTestBindings = map[string]any{
+	"dig_data": map[string]any{
+		"a": map[string]any{"b": []any{map[string]any{"c": "deep"}, map[string]any{"c": "last"}}},
+		"s": digStruct{map[string]any{"k": "v"}, []int{1}},
+		"n": map[int]string{10: "ten"},
+	},
 	"empty_array":     []any{},
 	"empty_map":       map[string]any{},
 	"empty_map_slice": yaml.MapSlice{},
+	"json_map":        map[string]any{"z": true, "b": map[string]any{"e": nil, "c": "d"}, "a": []int{1, 2}},
+	"json_struct": struct {
+		Title  string
+		Price  float64 `json:"price"`
+		hidden int
+	}{"Shirt", 10.5, 1},
 	"map": map[string]any{
 		"a": 1,
 	},
@@ -237,9 +666,59 @@ var filterTestBindings = map[string]any{
 		{"weight": 3},
 		{"weight": nil},
 	},
+	"nested":        []any{1, []any{2, []any{3, []int{4, 5}}}, "six", nil, []string{}, [][]int{{7}, {8}}},
+	"post_tags":     []string{"go", "templates", "liquid", "go"},
+	"selected_tags": []any{"liquid", "ruby", "go"},
+	"other_tags":    []string{"python", "rust"},
+	"int_floats":    []any{1.0, 4.0, "2"},
+	"mixed_join":    []any{1, "a", true, nil},
+	"float_join":    []any{2.5, 1e6, float32(-0.125)},
+	"sort_partial": []map[string]any{
+		{"name": "a", "priority": 2},
+		{"name": "b"},
+		{"name": "c", "priority": 1},
+		{"name": "d", "priority": 2},
+		{"name": "e", "priority": nil},
+		{"name": "f", "priority": 10},
+	},
+	"big":               1e20,
+	"sort_mixed_values": []any{10, "1a", 2, "0", 3, "b"},
+	"sort_mixed": []map[string]any{
+		{"key": 10},
+		{"key": "b"},
+		{"key": 2},
+		{"key": "a"},
+	},
 	"string_with_newlines": "\nHello\nthere\n",
+	"crlf_lines":           "\r\n\tone\r\n two\tthree \r\n",
+	"unicode_spaces":       "\t\u00a0\u2003x\u3000\t",
 	"dup_ints":             []int{1, 2, 1, 3},
-	"dup_strings":          []string{"one", "two", "one", "three"},
+	"summands":             []any{1, 2.5, uint8(3), "x", nil, true, int64(4)},
+	"string_summands":      []any{"3", 5, "a"},
+	"json_object":          `{"name": "Ann", "age": 42, "score": 9.5, "tags": ["a", "b"], "address": {"city": "Paris"}, "none": null}`,
+	"order_items": []map[string]any{
+		{"name": "book", "price": 12.5, "quantity": 2},
+		{"name": "pen", "price": 1.5, "quantity": 4},
+		{"name": "mug", "price": 8, "quantity": 1},
+	},
+	"line_items": []map[string]any{
+		{"price": 2.5, "quantity": 1},
+		{"price": "10", "quantity": 5},
+		{"title": "free"},
+	},
+	"dup_strings":     []string{"one", "two", "one", "three"},
+	"dup_numbers":     []any{1, 2, 1.0, uint8(2), 3, 3.0},
+	"natural_strings": []string{"banana", "Apple", "BANANA", "cherry", "Banana"},
+	"natural_structs": []struct {
+		ID    int    `liquid:"id"`
+		Title string `liquid:"title"`
+	}{{1, "zeta"}, {2, "Beta"}, {3, "beta"}, {4, "alpha"}},
+	"dup_nils":   []any{nil, "a", nil, "a"},
+	"dup_slices": []any{[]int{1, 2}, []any{1.0, 2}, []int{3}, []float64{3}},
+	"dup_structs": []struct {
+		ID   int    `liquid:"id"`
+		Name string `liquid:"name"`
+	}{{1, "a"}, {2, "b"}, {1, "c"}},
 
 	// for examples from liquid docs
 	"animals": []string{"zebra", "octopus", "giraffe", "Sally Snake"},
@@ -259,6 +738,23 @@ var filterTestBindings = map[string]any{
 		{"name": "page 6"},
 		{"name": "page 7", "category": "technology"},
 	},
+	"products": []map[string]any{
+		{"title": "Shirt", "type": "clothing", "available": true, "author": map[string]any{"name": "Ann"}},
+		{"title": "Spatula", "type": "kitchen", "available": false},
+		{"title": "Hat", "type": "clothing", "available": true},
+		{"title": "Pan"},
+	},
+	"product_structs": []struct {
+		Title     string `liquid:"title"`
+		Type      string `liquid:"type"`
+		Available bool   `liquid:"available"`
+	}{
+		{"Shirt", "clothing", true},
+		{"Spatula", "kitchen", false},
+		{"Hat", "clothing", true},
+	},
+	"html_blocks": "<style type=\"text/css\">p { color: red; }</style>Hello\n  <SCRIPT>alert('<b>hi</b>');\n</SCRIPT><!--\nnote\n-->world <br\n/>!",
+	"slice_array": []string{"a", "b", "c", "d", "e"},
 	"struct_slice": []struct {
 		Str string `liquid:"str"`
 	}{
@@ -268,6 +764,88 @@ var filterTestBindings = map[string]any{
 	},
 }
 
+func TestDateFilter_now(t *testing.T) {
+	cfg := expressions.NewConfig()
+	AddStandardFilters(&cfg)
+	context := expressions.NewContext(map[string]any{}, cfg)
+	for _, in := range []string{`"now" | date: "%Y"`, `"today" | date: "%Y"`} {
+		actual, err := expressions.EvaluateString(in, context)
+		require.NoError(t, err, in)
+		require.Equal(t, strconv.Itoa(time.Now().Year()), actual, in)
+	}
+
+	AddDateFilters(&cfg, func() *DateConfig { return &DateConfig{Location: time.FixedZone("X", 14*60*60)} })
+	actual, err := expressions.EvaluateString(`"now" | date: "%z"`, context)
+	require.NoError(t, err)
+	require.Equal(t, "+1400", actual)
+}
+
+func TestDateFilter_zone(t *testing.T) {
+	cfg := expressions.NewConfig()
+	dc := &DateConfig{}
+	AddDateFilters(&cfg, func() *DateConfig { return dc })
+	context := expressions.NewContext(map[string]any{"t": time.Date(2024, 10, 27, 14, 48, 0, 0, time.UTC)}, cfg)
+	for range 2 {
+		actual, err := expressions.EvaluateString(`t | date: "%H:%M", "Asia/Tokyo"`, context)
+		require.NoError(t, err)
+		require.Equal(t, "23:48", actual)
+	}
+	loc, ok := locations.Load("Asia/Tokyo")
+	require.True(t, ok, "the time zone is cached")
+	cached, err := loadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	require.Same(t, loc, cached)
+
+	dc = &DateConfig{Location: time.FixedZone("X", 60*60)}
+	actual, err := expressions.EvaluateString(`t | date: "%H:%M"`, context)
+	require.NoError(t, err)
+	require.Equal(t, "15:48", actual, "the filter reads the configuration when it is applied")
+}
+
+func TestDateFilter_directives(t *testing.T) {
+	cfg := expressions.NewConfig()
+	AddStandardFilters(&cfg)
+	bindings := map[string]any{
+		"t": time.Date(2015, 7, 5, 15, 4, 5, 0, time.FixedZone("EST", -5*60*60)),
+	}
+	context := expressions.NewContext(bindings, cfg)
+	tests := []struct{ format, expected string }{
+		{"%a", "Sun"},
+		{"%A", "Sunday"},
+		{"%b", "Jul"},
+		{"%B", "July"},
+		{"%d", "05"},
+		{"%-d", "5"},
+		{"%e", " 5"},
+		{"%H", "15"},
+		{"%I", "03"},
+		{"%-I", "3"},
+		{"%j", "186"},
+		{"%m", "07"},
+		{"%-m", "7"},
+		{"%M", "04"},
+		{"%p", "PM"},
+		{"%P", "pm"},
+		{"%S", "05"},
+		{"%U", "27"},
+		{"%w", "0"},
+		{"%W", "26"},
+		{"%y", "15"},
+		{"%Y", "2015"},
+		{"%Z", "EST"},
+		{"%z", "-0500"},
+		{"%%", "%"},
+		{"%q", "%q"},
+		{"100%", "100%"},
+		{"%Y-%m-%d %-H:%M", "2015-07-05 15:04"},
+	}
+	for _, test := range tests {
+		actual, err := expressions.EvaluateString(fmt.Sprintf("t | date: %q", test.format), context)
+		require.NoError(t, err, test.format)
+		require.Equal(t, test.expected, actual, test.format)
+	}
+}
+
 func TestFilters(t *testing.T) {
 	t.Setenv("TZ", "America/New_York")
 
@@ -277,6 +855,9 @@ func TestFilters(t *testing.T) {
 		m3 = map[string]any{"name": "m3"}
 	)
 	filterTestBindings["dup_maps"] = []any{m1, m2, m1, m3}
+	cyclic := map[string]any{}
+	cyclic["self"] = cyclic
+	filterTestBindings["cyclic"] = cyclic
 
 	cfg := expressions.NewConfig()
 	AddStandardFilters(&cfg)
@@ -298,6 +879,33 @@ func TestFilters(t *testing.T) {
 	}
 }
 
+func TestConcatFilter(t *testing.T) {
+	a, b := []int{1, 2}, []string{"x", "y"}
+	cfg := expressions.NewConfig()
+	AddStandardFilters(&cfg)
+	context := expressions.NewContext(map[string]any{"a": a, "b": b}, cfg)
+	actual, err := expressions.EvaluateString(`a | concat: b`, context)
+	require.NoError(t, err)
+	require.Equal(t, []any{1, 2, "x", "y"}, actual)
+	require.Equal(t, []int{1, 2}, a)
+	require.Equal(t, []string{"x", "y"}, b)
+}
+
+func TestReverseFilter(t *testing.T) {
+	a, s := []any{1, "x", nil}, "añb日"
+	cfg := expressions.NewConfig()
+	AddStandardFilters(&cfg)
+	context := expressions.NewContext(map[string]any{"a": a, "s": s}, cfg)
+	actual, err := expressions.EvaluateString(`a | reverse`, context)
+	require.NoError(t, err)
+	require.Equal(t, []any{nil, "x", 1}, actual)
+	require.Equal(t, []any{1, "x", nil}, a)
+	actual, err = expressions.EvaluateString(`s | reverse`, context)
+	require.NoError(t, err)
+	require.Equal(t, "日bña", actual)
+	require.Equal(t, "añb日", s)
+}
+
 func timeMustParse(s string) time.Time {
 	t, err := time.Parse(time.RFC3339, s)
 	if err != nil {
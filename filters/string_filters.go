@@ -0,0 +1,71 @@
+package filters
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// latinReplacer transliterates accented Latin letters, for handleize.
+var latinReplacer = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "ā", "a", "ă", "a", "ą", "a",
+	"æ", "ae", "ç", "c", "ć", "c", "č", "c", "ď", "d", "đ", "d", "ð", "d",
+	"è", "e", "é", "e", "ê", "e", "ë", "e", "ē", "e", "ė", "e", "ę", "e", "ě", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ī", "i", "į", "i", "ı", "i",
+	"ł", "l", "ľ", "l", "ñ", "n", "ń", "n", "ň", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "ō", "o", "ő", "o", "œ", "oe",
+	"ŕ", "r", "ř", "r", "ś", "s", "š", "s", "ş", "s", "ß", "ss", "ť", "t", "ţ", "t", "þ", "th",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ū", "u", "ů", "u", "ű", "u", "ų", "u",
+	"ý", "y", "ÿ", "y", "ź", "z", "ż", "z", "ž", "z",
+	"'", "", "’", "",
+)
+
+// handleizeFilter implements the handleize filter. It returns a URL handle: the
+// lowercase string with accented Latin letters transliterated, and each run of
+// other characters that aren't ASCII letters or digits replaced by a single hyphen.
+// Apostrophes are dropped, so that "Men's Shirts" is mens-shirts.
+func handleizeFilter(s string) string {
+	s = latinReplacer.Replace(strings.ToLower(s))
+	var b strings.Builder
+	hyphen := false
+	for _, r := range s {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		} else {
+			hyphen = true
+		}
+	}
+	return b.String()
+}
+
+// capitalizeFilter implements the capitalize filter. As with Ruby's
+// String#capitalize, the first character is upper case and the rest are
+// lower case; a first character that isn't a letter is unchanged.
+func capitalizeFilter(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToTitle(r)) + strings.ToLower(s[size:])
+}
+
+// titleizeFilter implements the titleize filter. It capitalizes each word: a
+// letter that follows a character other than a letter, digit, or apostrophe is
+// upper case, and the other letters are lower case.
+func titleizeFilter(s string) string {
+	var b strings.Builder
+	inWord := false
+	for _, r := range s {
+		if inWord {
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(unicode.ToTitle(r))
+		}
+		inWord = unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’'
+	}
+	return b.String()
+}
@@ -8,8 +8,11 @@ The liquid package itself is versioned in gopkg.in. Subpackages have no compatib
 package liquid
 
 import (
+	"github.com/osteele/liquid/expressions"
+	"github.com/osteele/liquid/filters"
 	"github.com/osteele/liquid/render"
 	"github.com/osteele/liquid/tags"
+	"github.com/osteele/liquid/values"
 )
 
 // Bindings is a map of variable names to values.
@@ -24,6 +27,10 @@ type Bindings map[string]any
 type Renderer func(render.Context) (string, error)
 
 // SourceError records an error with a source location and optional cause.
+// Path is the template path that was passed to ParseTemplateLocation, if any;
+// ColumnNumber is one-based, or zero if it is unknown.
+//
+// If the error has a path, it is formatted as "path:line:column: Liquid error: message".
 //
 // SourceError does not depend on, but is compatible with, the causer interface of https://github.com/pkg/errors.
 type SourceError interface {
@@ -31,8 +38,38 @@ type SourceError interface {
 	Cause() error
 	Path() string
 	LineNumber() int
+	ColumnNumber() int
+}
+
+// A Source is the location of a variable reference in a template. See Engine.SetUndefinedHandler.
+// Path is the template path that was passed to ParseTemplateLocation, if any.
+type Source struct {
+	Path   string
+	Line   int
+	Column int
 }
 
+// A MoneyFormat configures the money and money_with_currency filters. See Engine.SetMoneyFormat.
+type MoneyFormat = filters.MoneyFormat
+
+// DefaultMoneyFormat is the format of the money filters of a new Engine.
+var DefaultMoneyFormat = filters.DefaultMoneyFormat
+
+// A Warning is a problem that Template.RenderWithWarnings reports without stopping the render.
+type Warning = render.Warning
+
+// An OutputLimitError is the cause of the render error that is returned when
+// the output exceeds the size set by Engine.SetMaxOutputSize.
+type OutputLimitError = render.OutputLimitError
+
+// A MethodError is the cause of the render error that is returned when a template calls a
+// method, or a function-valued field, of a struct that returns an error, or whose signature a
+// template can't call: it must take no arguments and return a value, or a value and an error.
+type MethodError = values.MethodError
+
+// KeywordArgs holds the keyword arguments of a filter application. See Engine.RegisterFilter.
+type KeywordArgs = expressions.KeywordArgs
+
 // IterationKeyedMap returns a map whose {% for %} tag iteration values are its keys, instead of [key, value] pairs.
 // Use this to create a Go map with the semantics of a Ruby struct drop.
 func IterationKeyedMap(m map[string]any) tags.IterationKeyedMap {
@@ -16,6 +16,9 @@ type ASTBlock struct {
 	syntax  BlockSyntax
 	Body    []ASTNode   // Body is the nodes before the first branch
 	Clauses []*ASTBlock // E.g. else and elseif w/in an if
+	// InnerSource is the source text between the start and end tags,
+	// including that of any clauses.
+	InnerSource string
 }
 
 // ASTRaw holds the text between the start and end of a raw tag.
@@ -47,14 +50,22 @@ type ASTSeq struct {
 }
 
 // TrimDirection determines the trim direction of an ASTTrim object.
+//
+// Deprecated: The parser applies whitespace control to the adjacent text,
+// and no longer produces ASTTrim objects.
 type TrimDirection int
 
+// The trim directions.
+//
+// Deprecated: See TrimDirection.
 const (
 	Left TrimDirection = iota
 	Right
 )
 
 // ASTTrim is a trim object.
+//
+// Deprecated: The parser no longer produces these. See TrimDirection.
 type ASTTrim struct {
 	sourcelessNode
 	TrimDirection
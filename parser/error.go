@@ -8,6 +8,7 @@ type Error interface {
 	Cause() error
 	Path() string
 	LineNumber() int
+	ColumnNumber() int
 }
 
 // A Locatable provides source location information for error reporting.
@@ -29,7 +30,7 @@ func WrapError(err error, loc Locatable) Error {
 	if e, ok := err.(Error); ok {
 		// re-wrap the error, if the inner layer implemented the locatable interface
 		// but didn't actually provide any information
-		if e.Path() != "" || loc.SourceLocation().IsZero() {
+		if e.Path() != "" || e.ColumnNumber() > 0 || loc.SourceLocation().IsZero() {
 			return e
 		}
 		if e.Cause() != nil {
@@ -52,6 +53,11 @@ func (e *sourceLocError) Cause() error {
 	return e.cause
 }
 
+// Unwrap supports errors.Is and errors.As.
+func (e *sourceLocError) Unwrap() error {
+	return e.cause
+}
+
 func (e *sourceLocError) Path() string {
 	return e.Pathname
 }
@@ -60,14 +66,19 @@ func (e *sourceLocError) LineNumber() int {
 	return e.LineNo
 }
 
+func (e *sourceLocError) ColumnNumber() int {
+	return e.ColNo
+}
+
+// Error formats the error as "path:line:column: Liquid error: message" if the
+// source has a path, and otherwise as "Liquid error (line n): message in source".
 func (e *sourceLocError) Error() string {
+	if e.Pathname != "" {
+		return fmt.Sprintf("%s: Liquid error: %s", e.SourceLoc, e.message)
+	}
 	line := ""
 	if e.LineNo > 0 {
 		line = fmt.Sprintf(" (line %d)", e.LineNo)
 	}
-	locative := " in " + e.context
-	if e.Pathname != "" {
-		locative = " in " + e.Pathname
-	}
-	return fmt.Sprintf("Liquid error%s: %s%s", line, e.message, locative)
+	return fmt.Sprintf("Liquid error%s: %s in %s", line, e.message, e.context)
 }
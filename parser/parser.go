@@ -4,6 +4,7 @@ package parser
 import (
 	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/osteele/liquid/expressions"
 )
@@ -21,6 +22,7 @@ func (c Config) parseTokens(tokens []Token) (ASTNode, Error) { //nolint: gocyclo
 		syntax BlockSyntax
 		node   *ASTBlock
 		ap     *[]ASTNode
+		start  int // index in sources of the first token after the block start
 	}
 	var (
 		g         = c.Grammar
@@ -32,8 +34,22 @@ func (c Config) parseTokens(tokens []Token) (ASTNode, Error) { //nolint: gocyclo
 		rawTag    *ASTRaw          // current raw tag
 		inComment = false
 		inRaw     = false
+		trimNext  = false // trim leading whitespace from the next text token
+		// sources holds the source text of each token that was scanned; that
+		// is, of each token except those that replace a {% liquid %} tag
+		sources  []string
+		expanded = 0 // the number of remaining tokens from a {% liquid %} tag
 	)
-	for _, tok := range tokens {
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		synthetic := expanded > 0
+		if synthetic {
+			expanded--
+		} else {
+			sources = append(sources, tok.Source)
+		}
+		trim := trimNext
+		trimNext = false
 		switch {
 		// The parser needs to know about comment and raw, because tags inside
 		// needn't match each other e.g. {%comment%}{%if%}{%endcomment%}
@@ -55,7 +71,15 @@ func (c Config) parseTokens(tokens []Token) (ASTNode, Error) { //nolint: gocyclo
 			}
 			*ap = append(*ap, &ASTObject{tok, expr})
 		case tok.Type == TextTokenType:
+			if trim {
+				tok.Source = strings.TrimLeftFunc(tok.Source, unicode.IsSpace)
+			}
 			*ap = append(*ap, &ASTText{Token: tok})
+		case tok.Type == TagTokenType && tok.Name == "liquid":
+			// Replace {% liquid %} by the tags on its lines.
+			rest, lines := tokens[i+1:], liquidTagTokens(tok)
+			tokens = append(append(tokens[:i+1:i+1], lines...), rest...)
+			expanded = len(lines)
 		case tok.Type == TagTokenType:
 			if g == nil {
 				return nil, Errorf(tok, "Grammar field is nil")
@@ -76,7 +100,7 @@ func (c Config) parseTokens(tokens []Token) (ASTNode, Error) { //nolint: gocyclo
 					return nil, Errorf(tok, "%s not inside %s%s", tok.Name, strings.Join(cs.ParentTags(), " or "), suffix)
 				case cs.IsBlockStart():
 					push := func() {
-						stack = append(stack, frame{syntax: sd, node: bn, ap: ap})
+						stack = append(stack, frame{syntax: sd, node: bn, ap: ap, start: len(sources)})
 						sd, bn = cs, &ASTBlock{Token: tok, syntax: cs}
 						*ap = append(*ap, bn)
 					}
@@ -90,6 +114,9 @@ func (c Config) parseTokens(tokens []Token) (ASTNode, Error) { //nolint: gocyclo
 					pop := func() {
 						f := stack[len(stack)-1]
 						stack = stack[:len(stack)-1]
+						if end := len(sources) - 1; !synthetic && f.start <= end {
+							bn.InnerSource = strings.Join(sources[f.start:end], "")
+						}
 						sd, bn, ap = f.syntax, f.node, f.ap
 					}
 					pop()
@@ -100,9 +127,15 @@ func (c Config) parseTokens(tokens []Token) (ASTNode, Error) { //nolint: gocyclo
 				*ap = append(*ap, &ASTTag{tok})
 			}
 		case tok.Type == TrimLeftTokenType:
-			*ap = append(*ap, &ASTTrim{TrimDirection: Left})
+			// {%- and {{- remove the whitespace at the end of the preceding text
+			if n := len(*ap); n > 0 {
+				if t, ok := (*ap)[n-1].(*ASTText); ok {
+					t.Source = strings.TrimRightFunc(t.Source, unicode.IsSpace)
+				}
+			}
 		case tok.Type == TrimRightTokenType:
-			*ap = append(*ap, &ASTTrim{TrimDirection: Right})
+			// -%} and -}} remove the whitespace at the start of the following text
+			trimNext = true
 		}
 	}
 	if bn != nil {
@@ -110,3 +143,41 @@ func (c Config) parseTokens(tokens []Token) (ASTNode, Error) { //nolint: gocyclo
 	}
 	return root, nil
 }
+
+// liquidTagTokens returns the tags of a {% liquid %} tag, one per line of its
+// arguments. As in Shopify, blank lines and lines that begin with # are ignored.
+func liquidTagTokens(tok Token) []Token {
+	var (
+		tokens []Token
+		// offset is the offset of the current line in tok.Source
+		offset = strings.LastIndex(tok.Source, tok.Args)
+	)
+	for _, line := range strings.SplitAfter(tok.Args, "\n") {
+		start := offset + len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+		offset += len(line)
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		loc := tok.SourceLoc
+		prefix := tok.Source[:start]
+		loc.LineNo += strings.Count(prefix, "\n")
+		if i := strings.LastIndexByte(prefix, '\n'); i >= 0 {
+			loc.ColNo = start - i
+		} else if loc.ColNo > 0 {
+			loc.ColNo += start
+		}
+		name, args := line, ""
+		if i := strings.IndexFunc(line, unicode.IsSpace); i >= 0 {
+			name, args = line[:i], strings.TrimSpace(line[i:])
+		}
+		tokens = append(tokens, Token{
+			Type:      TagTokenType,
+			SourceLoc: loc,
+			Name:      name,
+			Args:      args,
+			Source:    line,
+		})
+	}
+	return tokens
+}
@@ -70,3 +70,35 @@ func TestParser(t *testing.T) {
 		})
 	}
 }
+
+func TestParse_liquidTag(t *testing.T) {
+	tokens := Scan("{{ a }}\n  {% liquid assign a = 1\n\n  # comment\n  if a\n  endif -%}", SourceLoc{Pathname: "f", LineNo: 1}, nil)
+	require.Len(t, tokens, 4)
+	lines := liquidTagTokens(tokens[2])
+	require.Equal(t, []Token{
+		{Type: TagTokenType, SourceLoc: SourceLoc{"f", 2, 13}, Name: "assign", Args: "a = 1", Source: "assign a = 1"},
+		{Type: TagTokenType, SourceLoc: SourceLoc{"f", 5, 3}, Name: "if", Args: "a", Source: "if a"},
+		{Type: TagTokenType, SourceLoc: SourceLoc{"f", 6, 3}, Name: "endif", Source: "endif"},
+	}, lines)
+
+	cfg := Config{Grammar: grammarFake{}}
+	_, err := cfg.Parse("{% liquid\n  if a\n  endif\n  endunless %}", SourceLoc{LineNo: 1})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not inside unless")
+	require.Equal(t, 4, err.LineNumber())
+}
+
+func TestParse_innerSource(t *testing.T) {
+	cfg := Config{Grammar: grammarFake{}}
+	ast, err := cfg.Parse("{% for x in y -%} a {{ x }}{% if x %}{% raw %}{% endif {{{% endraw %}{% else %}b{% endif %} {%- endfor %}c", SourceLoc{})
+	require.NoError(t, err)
+	block := ast.(*ASTSeq).Children[0].(*ASTBlock)
+	require.Equal(t, " a {{ x }}{% if x %}{% raw %}{% endif {{{% endraw %}{% else %}b{% endif %} ", block.InnerSource)
+	inner := block.Body[2].(*ASTBlock)
+	require.Equal(t, "{% raw %}{% endif {{{% endraw %}{% else %}b", inner.InnerSource)
+
+	ast, err = cfg.Parse("{% liquid if x\n endif %}{% unless y %}{% liquid if y\n endif %}{% endunless %}", SourceLoc{})
+	require.NoError(t, err)
+	require.Equal(t, "", ast.(*ASTSeq).Children[0].(*ASTBlock).InnerSource)
+	require.Equal(t, "{% liquid if y\n endif %}", ast.(*ASTSeq).Children[1].(*ASTBlock).InnerSource)
+}
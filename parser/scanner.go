@@ -17,12 +17,33 @@ func Scan(data string, loc SourceLoc, delims []string) (tokens []Token) {
 	// TODO error on unterminated {{ and {%
 	// TODO probably an error when a tag contains a {{ or {%, at least outside of a string
 	p, pe := 0, len(data)
-	for _, m := range tokenMatcher.FindAllStringSubmatchIndex(data, -1) {
+	// lineStart is the offset of the start of the line that contains p
+	lineStart := 0
+	advance := func(s string, offset int) {
+		loc.LineNo += strings.Count(s, "\n")
+		if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+			lineStart = offset + i + 1
+		}
+	}
+	// rawEnd matches {% endraw %}. It's compiled on the first {% raw %}.
+	var rawEnd *regexp.Regexp
+	for p < pe {
+		m := tokenMatcher.FindStringSubmatchIndex(data[p:])
+		if m == nil {
+			break
+		}
+		for i := range m {
+			if m[i] >= 0 {
+				m[i] += p
+			}
+		}
 		ts, te := m[0], m[1]
 		if p < ts {
+			loc.ColNo = p - lineStart + 1
 			tokens = append(tokens, Token{Type: TextTokenType, SourceLoc: loc, Source: data[p:ts]})
-			loc.LineNo += strings.Count(data[p:ts], "\n")
+			advance(data[p:ts], p)
 		}
+		loc.ColNo = ts - lineStart + 1
 		source := data[ts:te]
 		switch {
 		case data[ts:ts+len(delims[0])] == delims[0]:
@@ -63,11 +84,26 @@ func Scan(data string, loc SourceLoc, delims []string) (tokens []Token) {
 					Type: TrimRightTokenType,
 				})
 			}
+			if tok.Name == "raw" {
+				// The body of a raw tag is text up to the next {% endraw %},
+				// even if it contains unbalanced or invalid delimiters.
+				if rawEnd == nil {
+					rawEnd = formRawEndMatcher(delims)
+				}
+				if e := rawEnd.FindStringIndex(data[te:]); e != nil && e[0] > 0 {
+					advance(source, ts)
+					loc.ColNo = te - lineStart + 1
+					tokens = append(tokens, Token{Type: TextTokenType, SourceLoc: loc, Source: data[te : te+e[0]]})
+					ts, te = te, te+e[0]
+					source = data[ts:te]
+				}
+			}
 		}
-		loc.LineNo += strings.Count(source, "\n")
+		advance(source, ts)
 		p = te
 	}
 	if p < pe {
+		loc.ColNo = p - lineStart + 1
 		tokens = append(tokens, Token{Type: TextTokenType, SourceLoc: loc, Source: data[p:]})
 	}
 	return tokens
@@ -97,3 +133,9 @@ func formTokenMatcher(delims []string) *regexp.Regexp {
 
 	return tokenMatcher
 }
+
+// formRawEndMatcher returns a regular expression that matches the tag that
+// ends a raw block.
+func formRawEndMatcher(delims []string) *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(delims[2]) + `-?\s*endraw\s*-?` + regexp.QuoteMeta(delims[3]))
+}
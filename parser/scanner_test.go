@@ -77,9 +77,10 @@ func TestScan_ws(t *testing.T) {
 	}{
 		{`{{ expr }}`, []Token{
 			{
-				Type:   ObjTokenType,
-				Args:   "expr",
-				Source: "{{ expr }}",
+				Type:      ObjTokenType,
+				SourceLoc: SourceLoc{ColNo: 1},
+				Args:      "expr",
+				Source:    "{{ expr }}",
 			},
 		}},
 		{`{{- expr }}`, []Token{
@@ -87,16 +88,18 @@ func TestScan_ws(t *testing.T) {
 				Type: TrimLeftTokenType,
 			},
 			{
-				Type:   ObjTokenType,
-				Args:   "expr",
-				Source: "{{- expr }}",
+				Type:      ObjTokenType,
+				SourceLoc: SourceLoc{ColNo: 1},
+				Args:      "expr",
+				Source:    "{{- expr }}",
 			},
 		}},
 		{`{{ expr -}}`, []Token{
 			{
-				Type:   ObjTokenType,
-				Args:   "expr",
-				Source: "{{ expr -}}",
+				Type:      ObjTokenType,
+				SourceLoc: SourceLoc{ColNo: 1},
+				Args:      "expr",
+				Source:    "{{ expr -}}",
 			},
 			{
 				Type: TrimRightTokenType,
@@ -107,9 +110,10 @@ func TestScan_ws(t *testing.T) {
 				Type: TrimLeftTokenType,
 			},
 			{
-				Type:   ObjTokenType,
-				Args:   "expr",
-				Source: "{{- expr -}}",
+				Type:      ObjTokenType,
+				SourceLoc: SourceLoc{ColNo: 1},
+				Args:      "expr",
+				Source:    "{{- expr -}}",
 			},
 			{
 				Type: TrimRightTokenType,
@@ -117,10 +121,11 @@ func TestScan_ws(t *testing.T) {
 		}},
 		{`{% tag arg %}`, []Token{
 			{
-				Type:   TagTokenType,
-				Name:   "tag",
-				Args:   "arg",
-				Source: "{% tag arg %}",
+				Type:      TagTokenType,
+				SourceLoc: SourceLoc{ColNo: 1},
+				Name:      "tag",
+				Args:      "arg",
+				Source:    "{% tag arg %}",
 			},
 		}},
 		{`{%- tag arg %}`, []Token{
@@ -128,18 +133,20 @@ func TestScan_ws(t *testing.T) {
 				Type: TrimLeftTokenType,
 			},
 			{
-				Type:   TagTokenType,
-				Name:   "tag",
-				Args:   "arg",
-				Source: "{%- tag arg %}",
+				Type:      TagTokenType,
+				SourceLoc: SourceLoc{ColNo: 1},
+				Name:      "tag",
+				Args:      "arg",
+				Source:    "{%- tag arg %}",
 			},
 		}},
 		{`{% tag arg -%}`, []Token{
 			{
-				Type:   TagTokenType,
-				Name:   "tag",
-				Args:   "arg",
-				Source: "{% tag arg -%}",
+				Type:      TagTokenType,
+				SourceLoc: SourceLoc{ColNo: 1},
+				Name:      "tag",
+				Args:      "arg",
+				Source:    "{% tag arg -%}",
 			},
 			{
 				Type: TrimRightTokenType,
@@ -154,6 +161,43 @@ func TestScan_ws(t *testing.T) {
 	}
 }
 
+func TestScan_columns(t *testing.T) {
+	tokens := Scan("ab{{ x }}\n  {% if y %}\n{{ z }}", SourceLoc{Pathname: "f.html", LineNo: 1}, nil)
+	var locs []SourceLoc
+	for _, tok := range tokens {
+		locs = append(locs, tok.SourceLoc)
+	}
+	require.Equal(t, []SourceLoc{
+		{"f.html", 1, 1},
+		{"f.html", 1, 3},
+		{"f.html", 1, 10},
+		{"f.html", 2, 3},
+		{"f.html", 2, 13},
+		{"f.html", 3, 1},
+	}, locs)
+	require.Equal(t, "f.html:2:3", locs[3].String())
+}
+
+func TestScan_raw(t *testing.T) {
+	scan := func(src string) []Token { return Scan(src, SourceLoc{}, nil) }
+	tokens := scan("{% raw %}{{ var }}{% if %}{% endraw %}")
+	require.Equal(t, `[TagTokenType{Tag:"raw", Args:""} TextTokenType{"{{ var }}{% if %}"} TagTokenType{Tag:"endraw", Args:""}]`, fmt.Sprint(tokens))
+
+	// unbalanced delimiters don't consume the endraw tag
+	tokens = scan("{% raw %}{% if {{ x {%- endraw %}{{ y }}")
+	require.Equal(t, `[TagTokenType{Tag:"raw", Args:""} TextTokenType{"{% if {{ x "} - TagTokenType{Tag:"endraw", Args:""} ObjTokenType{"y"}]`, fmt.Sprint(tokens))
+
+	tokens = Scan("{% raw %}\n{% x\n {% endraw %}{{ y }}", SourceLoc{LineNo: 1}, nil)
+	var locs []SourceLoc
+	for _, tok := range tokens {
+		locs = append(locs, tok.SourceLoc)
+	}
+	require.Equal(t, []SourceLoc{{"", 1, 1}, {"", 1, 10}, {"", 3, 2}, {"", 3, 14}}, locs)
+
+	tokens = Scan("TAG*LEFT raw TAG!RIGHTTAG*LEFT x TAG*LEFTendrawTAG!RIGHT", SourceLoc{}, []string{"OBJECT@LEFT", "OBJECT#RIGHT", "TAG*LEFT", "TAG!RIGHT"})
+	require.Equal(t, `[TagTokenType{Tag:"raw", Args:""} TextTokenType{"TAG*LEFT x "} TagTokenType{Tag:"endraw", Args:""}]`, fmt.Sprint(tokens))
+}
+
 var scannerCountTestsDelims = []struct {
 	in  string
 	len int
@@ -31,10 +31,12 @@ const (
 
 // SourceLoc contains a Token's source location. Pathname is in the local file
 // system; for example "dir/file.html" on Linux and macOS; "dir\file.html" on
-// Windows.
+// Windows. ColNo is the one-based column of the token's first character, or
+// zero if it is unknown.
 type SourceLoc struct {
 	Pathname string
 	LineNo   int
+	ColNo    int
 }
 
 // SourceLocation returns the token's source location, for use in error reporting.
@@ -64,8 +66,13 @@ func (c Token) String() string {
 }
 
 func (s SourceLoc) String() string {
-	if s.Pathname != "" {
+	switch {
+	case s.Pathname != "" && s.ColNo > 0:
+		return fmt.Sprintf("%s:%d:%d", s.Pathname, s.LineNo, s.ColNo)
+	case s.Pathname != "":
 		return fmt.Sprintf("%s:%d", s.Pathname, s.LineNo)
+	case s.ColNo > 0:
+		return fmt.Sprintf("line %d, column %d", s.LineNo, s.ColNo)
 	}
 	return fmt.Sprintf("line %d", s.LineNo)
 }
@@ -57,6 +57,19 @@ func (g grammar) findBlockDef(name string) (*blockSyntax, bool) {
 	return ct, found
 }
 
+// BlockNames returns the sorted names of the blocks that have been added with
+// AddBlock. These don't include end tags and clauses.
+func (g grammar) BlockNames() []string {
+	var names []string
+	for name, ct := range g.blockDefs {
+		if ct.IsBlockStart() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // BlockSyntax is part of the Grammar interface.
 func (g grammar) BlockSyntax(name string) (parser.BlockSyntax, bool) {
 	ct, found := g.blockDefs[name]
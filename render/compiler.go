@@ -19,6 +19,9 @@ func (c Config) Compile(source string, loc parser.SourceLoc) (Node, parser.Error
 func (c Config) compileNode(n parser.ASTNode) (Node, parser.Error) {
 	switch n := n.(type) {
 	case *parser.ASTBlock:
+		if err := c.CheckFilters(n.Args); err != nil {
+			return nil, parser.WrapError(err, n)
+		}
 		body, err := c.compileNodes(n.Body)
 		if err != nil {
 			return nil, err
@@ -33,9 +36,10 @@ func (c Config) compileNode(n parser.ASTNode) (Node, parser.Error) {
 			return nil, parser.Errorf(n, "undefined tag %q", n.Name)
 		}
 		node := BlockNode{
-			Token:   n.Token,
-			Body:    body,
-			Clauses: branches,
+			Token:       n.Token,
+			Body:        body,
+			Clauses:     branches,
+			InnerSource: n.InnerSource,
 		}
 		if cd.parser != nil {
 			r, err := cd.parser(node)
@@ -54,6 +58,9 @@ func (c Config) compileNode(n parser.ASTNode) (Node, parser.Error) {
 		}
 		return &SeqNode{children, sourcelessNode{}}, nil
 	case *parser.ASTTag:
+		if err := c.CheckFilters(n.Args); err != nil {
+			return nil, parser.WrapError(err, n)
+		}
 		if td, ok := c.FindTagDefinition(n.Name); ok {
 			f, err := td(n.Args)
 			if err != nil {
@@ -65,6 +72,9 @@ func (c Config) compileNode(n parser.ASTNode) (Node, parser.Error) {
 	case *parser.ASTText:
 		return &TextNode{n.Token}, nil
 	case *parser.ASTObject:
+		if err := c.CheckFilters(n.Args); err != nil {
+			return nil, parser.WrapError(err, n)
+		}
 		return &ObjectNode{n.Token, n.Expr}, nil
 	case *parser.ASTTrim:
 		return &TrimNode{TrimDirection: n.TrimDirection}, nil
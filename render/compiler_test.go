@@ -1,6 +1,7 @@
 package render
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -40,3 +41,17 @@ func TestCompile_errors(t *testing.T) {
 		})
 	}
 }
+
+func TestCompile_trim(t *testing.T) {
+	// The parser no longer produces trim nodes, but another one may.
+	seq := &parser.ASTSeq{Children: []parser.ASTNode{
+		&parser.ASTText{Token: parser.Token{Source: "a "}},
+		&parser.ASTTrim{TrimDirection: parser.Left},
+		&parser.ASTText{Token: parser.Token{Source: " b"}},
+	}}
+	root, err := NewConfig().compileNode(seq)
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	require.NoError(t, Render(root, buf, map[string]any{}, NewConfig()))
+	require.Equal(t, "a  b", buf.String())
+}
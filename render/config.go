@@ -4,12 +4,33 @@ import (
 	"github.com/osteele/liquid/parser"
 )
 
+// DefaultMaxIncludeDepth is the default value of Config.MaxIncludeDepth.
+const DefaultMaxIncludeDepth = 100
+
 // Config holds configuration information for parsing and rendering.
 type Config struct {
 	parser.Config
 	grammar
-	Cache           map[string][]byte
-	StrictVariables bool
+	Cache map[string][]byte
+	// MaxIncludeDepth limits the nesting of {% include %} and {% render %}, so that
+	// a partial that includes itself fails instead of exhausting the stack.
+	// Zero or less means no limit.
+	MaxIncludeDepth int
+	// MaxOutputSize limits the number of bytes that a render may produce.
+	// Zero or less means no limit.
+	MaxOutputSize int
+	// UndefinedHandler, if set, is called with the dotted path and the source location
+	// of a reference to an undefined variable or property. See expressions.Config.Undefined.
+	UndefinedHandler func(path string, loc parser.SourceLoc) (any, bool)
+	// Globals are variables that every render can read, including the renders of
+	// partials. A binding with the same name takes precedence.
+	Globals map[string]any
+	// StrictLoopVariables causes {% assign %} and {% capture %} to fail, instead
+	// of doing nothing, when they assign to forloop or tablerowloop inside a loop.
+	StrictLoopVariables bool
+
+	partialResolver PartialResolver
+	partials        *partialCache
 }
 
 type grammar struct {
@@ -23,5 +44,10 @@ func NewConfig() Config {
 		tags:      map[string]TagCompiler{},
 		blockDefs: map[string]*blockSyntax{},
 	}
-	return Config{Config: parser.NewConfig(g), grammar: g, Cache: map[string][]byte{}}
+	return Config{
+		Config:          parser.NewConfig(g),
+		grammar:         g,
+		Cache:           map[string][]byte{},
+		MaxIncludeDepth: DefaultMaxIncludeDepth,
+	}
 }
@@ -2,6 +2,7 @@ package render
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -17,6 +18,9 @@ type Context interface {
 	Bindings() map[string]any
 	// Get retrieves the value of a variable from the current lexical environment.
 	Get(name string) any
+	// ForLoop returns the state of the innermost {% for %} loop that contains the
+	// current tag, or nil if there isn't one.
+	ForLoop() *ForLoop
 	// Errorf creates a SourceError, that includes the source location.
 	// Use this to distinguish errors in the template from implementation errors
 	// in the template engine.
@@ -31,6 +35,9 @@ type Context interface {
 	// InnerString is the rendered content of the current block.
 	// It's used in the implementation of the Liquid "capture" tag and the Jekyll "highlght" tag.
 	InnerString() (string, error)
+	// InnerSource is the source text of the current block, between its start and end tags.
+	// It is not rendered.
+	InnerSource() string
 	// RenderBlock is used in the implementation of the built-in control flow tags.
 	// It's not guaranteed stable.
 	RenderBlock(io.Writer, *BlockNode) error
@@ -38,11 +45,22 @@ type Context interface {
 	// It's not guaranteed stable.
 	RenderChildren(io.Writer) Error
 	// RenderFile parses and renders a template. It's used in the implementation of the {% include %} tag.
-	// RenderFile does not cache the compiled template.
+	// RenderFile does not cache the compiled template, unless it's found by the Config's PartialResolver.
 	RenderFile(string, map[string]any) (string, error)
+	// RenderFileIsolated is like RenderFile, except that the template sees only the
+	// bindings that are passed to it, and not those of the current context.
+	// It's used in the implementation of the {% render %} tag.
+	RenderFileIsolated(string, map[string]any) (string, error)
+	// RenderFileTo and RenderFileIsolatedTo are like RenderFile and RenderFileIsolated,
+	// except that they write the output to the writer as it is rendered.
+	RenderFileTo(io.Writer, string, map[string]any) error
+	RenderFileIsolatedTo(io.Writer, string, map[string]any) error
 	// Set updates the value of a variable in the current lexical environment.
 	// It's used in the implementation of the {% assign %} and {% capture %} tags.
 	Set(name string, value any)
+	// StrictLoopVariables reports whether an assignment to a loop variable, such as
+	// forloop, inside a loop is an error. See Config.StrictLoopVariables.
+	StrictLoopVariables() bool
 	// SourceFile retrieves the value set by template.SetSourcePath.
 	// It's used in the implementation of the {% include %} tag.
 	SourceFile() string
@@ -73,35 +91,33 @@ func (i invalidLocation) SourceText() string {
 
 var invalidLoc parser.Locatable = invalidLocation{}
 
-func (c rendererContext) Errorf(format string, a ...any) Error {
+// loc returns the location of the current node.
+func (c rendererContext) loc() parser.Locatable {
 	switch {
 	case c.node != nil:
-		return renderErrorf(c.node, format, a...)
+		return c.node
 	case c.cn != nil:
-		return renderErrorf(c.cn, format, a...)
+		return c.cn
 	default:
-		return renderErrorf(invalidLoc, format, a...)
+		return invalidLoc
 	}
 }
 
+func (c rendererContext) Errorf(format string, a ...any) Error {
+	return renderErrorf(c.loc(), format, a...)
+}
+
 func (c rendererContext) WrapError(err error) Error {
-	switch {
-	case c.node != nil:
-		return wrapRenderError(err, c.node)
-	case c.cn != nil:
-		return wrapRenderError(err, c.cn)
-	default:
-		return wrapRenderError(err, invalidLoc)
-	}
+	return wrapRenderError(err, c.loc())
 }
 
 func (c rendererContext) Evaluate(expr expressions.Expression) (out any, err error) {
-	return c.ctx.Evaluate(expr)
+	return c.ctx.Evaluate(expr, c.loc())
 }
 
 // EvaluateString evaluates an expression within the template context.
 func (c rendererContext) EvaluateString(source string) (out any, err error) {
-	return expressions.EvaluateString(source, expressions.NewContext(c.ctx.bindings, c.ctx.config.Config.Config))
+	return expressions.EvaluateString(source, c.ctx.expressionContext(c.loc()))
 }
 
 // Bindings returns the current lexical environment.
@@ -114,15 +130,20 @@ func (c rendererContext) Get(name string) any {
 	return c.ctx.bindings[name]
 }
 
+// ForLoop returns the state of the innermost loop.
+func (c rendererContext) ForLoop() *ForLoop {
+	return forLoopOf(c.ctx.bindings["forloop"])
+}
+
 func (c rendererContext) ExpandTagArg() (string, error) {
 	args := c.TagArgs()
 	if strings.Contains(args, "{{") {
-		root, err := c.ctx.config.Compile(args, c.node.SourceLoc)
+		root, err := c.ctx.config.Compile(args, c.loc().SourceLocation())
 		if err != nil {
 			return "", err
 		}
 		buf := new(bytes.Buffer)
-		err = Render(root, buf, c.ctx.bindings, c.ctx.config)
+		err = renderNode(root, buf, c.ctx.nested(c.ctx.bindings))
 		if err != nil {
 			return "", err
 		}
@@ -141,25 +162,30 @@ func (c rendererContext) RenderChildren(w io.Writer) Error {
 	if c.cn == nil {
 		return nil
 	}
+	// loop tags call this once per iteration
+	if err := c.ctx.checkDone(c.cn); err != nil {
+		return err
+	}
 	return c.ctx.RenderSequence(w, c.cn.Body)
 }
 
 func (c rendererContext) RenderFile(filename string, b map[string]any) (string, error) {
-	source, err := os.ReadFile(filename)
-	if err != nil && os.IsNotExist(err) {
-		// Is it cached?
-		if cval, ok := c.ctx.config.Cache[filename]; ok {
-			source = cval
-		} else {
-			return "", err
-		}
-	} else if err != nil {
+	buf := new(bytes.Buffer)
+	if err := c.RenderFileTo(buf, filename, b); err != nil {
 		return "", err
 	}
-	root, err := c.ctx.config.Compile(string(source), c.node.SourceLoc)
-	if err != nil {
+	return buf.String(), nil
+}
+
+func (c rendererContext) RenderFileIsolated(filename string, b map[string]any) (string, error) {
+	buf := new(bytes.Buffer)
+	if err := c.RenderFileIsolatedTo(buf, filename, b); err != nil {
 		return "", err
 	}
+	return buf.String(), nil
+}
+
+func (c rendererContext) RenderFileTo(w io.Writer, filename string, b map[string]any) error {
 	bindings := map[string]any{}
 	for k, v := range c.ctx.bindings {
 		bindings[k] = v
@@ -167,11 +193,57 @@ func (c rendererContext) RenderFile(filename string, b map[string]any) (string,
 	for k, v := range b {
 		bindings[k] = v
 	}
-	buf := new(bytes.Buffer)
-	if err := Render(root, buf, bindings, c.ctx.config); err != nil {
-		return "", err
+	return c.renderFile(w, filename, bindings)
+}
+
+func (c rendererContext) RenderFileIsolatedTo(w io.Writer, filename string, b map[string]any) error {
+	bindings := map[string]any{}
+	for k, v := range b {
+		bindings[k] = v
 	}
-	return buf.String(), nil
+	if chain, ok := c.ctx.bindings[partialChainVarName]; ok {
+		bindings[partialChainVarName] = chain
+	}
+	return c.renderFile(w, filename, bindings)
+}
+
+// partialChainVarName holds the names of the enclosing included or rendered files,
+// outermost first.
+const partialChainVarName = ".partials"
+
+func (c rendererContext) renderFile(w io.Writer, filename string, bindings map[string]any) error {
+	chain, _ := bindings[partialChainVarName].([]string)
+	chain = append(chain[:len(chain):len(chain)], filename)
+	if limit := c.ctx.config.MaxIncludeDepth; limit > 0 && len(chain) > limit {
+		return fmt.Errorf("include depth limit of %d exceeded: %s", limit, strings.Join(chain, " > "))
+	}
+	bindings[partialChainVarName] = chain
+	root, err := c.compileFile(filename)
+	if err != nil {
+		return err
+	}
+	if err := renderNode(root, w, c.ctx.nested(bindings)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c rendererContext) compileFile(filename string) (Node, error) {
+	if c.ctx.config.partialResolver != nil {
+		return c.ctx.config.compilePartial(filename)
+	}
+	source, err := os.ReadFile(filename)
+	if err != nil && os.IsNotExist(err) {
+		// Is it cached?
+		if cval, ok := c.ctx.config.Cache[filename]; ok {
+			source = cval
+		} else {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return c.ctx.config.Compile(string(source), c.loc().SourceLocation())
 }
 
 // InnerString renders the children to a string.
@@ -183,11 +255,23 @@ func (c rendererContext) InnerString() (string, error) {
 	return buf.String(), nil
 }
 
+// InnerSource returns the source text of the current block.
+func (c rendererContext) InnerSource() string {
+	if c.cn == nil {
+		return ""
+	}
+	return c.cn.InnerSource
+}
+
 // Set sets a variable value from an evaluation context.
 func (c rendererContext) Set(name string, value any) {
 	c.ctx.bindings[name] = value
 }
 
+func (c rendererContext) StrictLoopVariables() bool {
+	return c.ctx.config.StrictLoopVariables
+}
+
 func (c rendererContext) SourceFile() string {
 	switch {
 	case c.node != nil:
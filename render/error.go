@@ -2,12 +2,14 @@ package render
 
 import (
 	"github.com/osteele/liquid/parser"
+	"github.com/osteele/liquid/values"
 )
 
 // An Error is an error during template rendering.
 type Error interface {
 	Path() string
 	LineNumber() int
+	ColumnNumber() int
 	Cause() error
 	Error() string
 }
@@ -19,3 +21,16 @@ func renderErrorf(loc parser.Locatable, format string, a ...any) Error {
 func wrapRenderError(err error, loc parser.Locatable) Error {
 	return parser.WrapError(err, loc)
 }
+
+// recoverTypeError turns a values.TypeError panic, for example from Value.Int() in
+// a tag implementation, into a render error at loc. Other panics are re-raised.
+// Use it as a deferred call, with a pointer to a named error return value.
+func recoverTypeError(loc parser.Locatable, errp *Error) {
+	if r := recover(); r != nil {
+		e, ok := r.(values.TypeError)
+		if !ok {
+			panic(r)
+		}
+		*errp = wrapRenderError(e, loc)
+	}
+}
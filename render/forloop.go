@@ -0,0 +1,36 @@
+package render
+
+// ForLoop is the state of the innermost {% for %} loop, as it is seen by
+// the template through the forloop variable.
+type ForLoop struct {
+	Index, Index0   int
+	Rindex, Rindex0 int
+	Length          int
+	First, Last     bool
+	// Parent is the state of the enclosing loop, or nil.
+	Parent *ForLoop
+}
+
+// forLoopOf returns the ForLoop for the value of a forloop variable, or nil
+// if v isn't a forloop object.
+func forLoopOf(v any) *ForLoop {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	index0, ok := m["index0"].(int)
+	if !ok {
+		return nil
+	}
+	length, _ := m["length"].(int)
+	return &ForLoop{
+		Index:   index0 + 1,
+		Index0:  index0,
+		Rindex:  length - index0,
+		Rindex0: length - index0 - 1,
+		Length:  length,
+		First:   index0 == 0,
+		Last:    index0 == length-1,
+		Parent:  forLoopOf(m["parentloop"]),
+	}
+}
@@ -0,0 +1,34 @@
+package render
+
+import (
+	"fmt"
+	"io"
+)
+
+// An OutputLimitError is returned when the rendered output grows beyond
+// Config.MaxOutputSize.
+type OutputLimitError struct {
+	Limit int
+}
+
+func (e OutputLimitError) Error() string {
+	return fmt.Sprintf("output exceeds the limit of %d bytes", e.Limit)
+}
+
+// A limitWriter wraps an io.Writer. If limit is positive, writes fail with an
+// OutputLimitError once more than limit bytes would have been written to w.
+type limitWriter struct {
+	w     io.Writer
+	limit int
+	n     int
+}
+
+// Write writes b to w, enforcing the output limit.
+func (lw *limitWriter) Write(b []byte) (int, error) {
+	if lw.limit > 0 && lw.n+len(b) > lw.limit {
+		return 0, OutputLimitError{lw.limit}
+	}
+	n, err := lw.w.Write(b)
+	lw.n += n
+	return n, err
+}
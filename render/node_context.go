@@ -1,7 +1,10 @@
 package render
 
 import (
+	"context"
+
 	"github.com/osteele/liquid/expressions"
+	"github.com/osteele/liquid/parser"
 )
 
 // nodeContext provides the evaluation context for rendering the AST.
@@ -11,20 +14,74 @@ import (
 type nodeContext struct {
 	bindings map[string]any
 	config   Config
+	goctx    context.Context
+	warnings *[]Warning // nil unless the render collects warnings
 }
 
 // newNodeContext creates a new evaluation context.
-func newNodeContext(scope map[string]any, c Config) nodeContext {
+func newNodeContext(goctx context.Context, scope map[string]any, c Config) nodeContext {
 	// The assign tag modifies the scope, so make a copy first.
 	// TODO this isn't really the right place for this.
 	vars := map[string]any{}
+	for k, v := range c.Globals {
+		vars[k] = v
+	}
 	for k, v := range scope {
 		vars[k] = v
 	}
-	return nodeContext{vars, c}
+	return nodeContext{vars, c, goctx, nil}
+}
+
+// nested creates the evaluation context for another template, such as an
+// included file, that is rendered as part of the same render.
+func (c nodeContext) nested(scope map[string]any) nodeContext {
+	nc := newNodeContext(c.goctx, scope, c.config)
+	nc.warnings = c.warnings
+	return nc
+}
+
+// checkDone returns an error at loc if the render's context.Context is done.
+// This is a non-blocking channel receive, so it's cheap enough to call at
+// every tag and loop iteration.
+func (c nodeContext) checkDone(loc parser.Locatable) Error {
+	select {
+	case <-c.goctx.Done():
+		return wrapRenderError(c.goctx.Err(), loc)
+	default:
+		return nil
+	}
 }
 
 // Evaluate evaluates an expression within the template context.
-func (c nodeContext) Evaluate(expr expressions.Expression) (out any, err error) {
-	return expr.Evaluate(expressions.NewContext(c.bindings, c.config.Config.Config))
+// loc locates any warnings.
+func (c nodeContext) Evaluate(expr expressions.Expression, loc parser.Locatable) (out any, err error) {
+	return expr.Evaluate(c.expressionContext(loc))
+}
+
+func (c nodeContext) expressionContext(loc parser.Locatable) expressions.Context {
+	cfg := c.config.Config.Config
+	if c.warnings != nil {
+		cfg.Warn = func(err error) {
+			*c.warnings = append(*c.warnings, newWarning(err, loc))
+		}
+	}
+	if h := c.config.UndefinedHandler; h != nil {
+		cfg.Undefined = func(path string) (any, bool) {
+			return h(path, loc.SourceLocation())
+		}
+	}
+	cfg.FilterContext = c.rendererContext(loc)
+	return expressions.NewContext(c.bindings, cfg)
+}
+
+// rendererContext returns the Context of the tag or block at loc.
+func (c nodeContext) rendererContext(loc parser.Locatable) Context {
+	rc := rendererContext{ctx: c}
+	switch n := loc.(type) {
+	case *TagNode:
+		rc.node = n
+	case *BlockNode:
+		rc.cn = n
+	}
+	return rc
 }
@@ -11,7 +11,7 @@ import (
 type Node interface {
 	SourceLocation() parser.SourceLoc // for error reporting
 	SourceText() string               // for error reporting
-	render(*trimWriter, nodeContext) Error
+	render(*limitWriter, nodeContext) Error
 }
 
 // BlockNode represents a {% tag %}…{% endtag %}.
@@ -20,6 +20,8 @@ type BlockNode struct {
 	renderer func(io.Writer, Context) error
 	Body     []Node
 	Clauses  []*BlockNode
+	// InnerSource is the source text between the start and end tags.
+	InnerSource string
 }
 
 // RawNode holds the text between the start and end of a raw tag.
@@ -52,6 +54,9 @@ type SeqNode struct {
 }
 
 // TrimNode is a trim object.
+//
+// Deprecated: Whitespace control is applied to the adjacent text when the
+// template is parsed. A TrimNode renders nothing.
 type TrimNode struct {
 	sourcelessNode
 	parser.TrimDirection
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/osteele/liquid/parser"
+)
+
+// A PartialResolver returns the source of the partial template with the given
+// name. The name is the argument of an {% include %} or {% render %} tag,
+// relative to the directory of the including template.
+type PartialResolver func(name string) ([]byte, error)
+
+// SetPartialResolver sets the function that {% include %} and {% render %} use to
+// find partial templates, in place of the file system and Cache. The compiled
+// partials are cached by name; setting a resolver clears this cache.
+func (c *Config) SetPartialResolver(fn PartialResolver) {
+	c.partialResolver = fn
+	c.partials = &partialCache{nodes: map[string]Node{}}
+}
+
+// RenderPartial renders the partial template with the given name, as {% render %}
+// does: it's found by the partial resolver, or else read from the file system or
+// Cache, and it sees only vars and c.Globals.
+func RenderPartial(w io.Writer, name string, vars map[string]any, c Config) Error {
+	rc := rendererContext{ctx: newNodeContext(context.Background(), nil, c)}
+	return wrapRenderError(rc.RenderFileIsolatedTo(w, name, vars), invalidLoc)
+}
+
+// partialCache holds the compiled partials. It's safe for concurrent use.
+type partialCache struct {
+	sync.Mutex
+	nodes map[string]Node
+}
+
+// compilePartial returns the compiled partial with the given name, from the
+// cache if possible.
+func (c Config) compilePartial(name string) (Node, error) {
+	c.partials.Lock()
+	root, ok := c.partials.nodes[name]
+	c.partials.Unlock()
+	if ok {
+		return root, nil
+	}
+	source, err := c.partialResolver(name)
+	if err != nil {
+		return nil, err
+	}
+	root, err = c.Compile(string(source), parser.SourceLoc{Pathname: name, LineNo: 1})
+	if err != nil {
+		return nil, err
+	}
+	c.partials.Lock()
+	c.partials.nodes[name] = root
+	c.partials.Unlock()
+	return root, nil
+}
@@ -2,48 +2,50 @@
 package render
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"io"
-	"reflect"
-	"strconv"
-	"time"
-
-	"github.com/osteele/liquid/parser"
 
 	"github.com/osteele/liquid/values"
 )
 
 // Render renders the render tree.
 func Render(node Node, w io.Writer, vars map[string]any, c Config) Error {
-	tw := trimWriter{w: w}
-	if err := node.render(&tw, newNodeContext(vars, c)); err != nil {
-		return err
-	}
-	if _, err := tw.Flush(); err != nil {
-		panic(err)
-	}
-	return nil
+	return RenderContext(context.Background(), node, w, vars, c)
+}
+
+// RenderContext renders the render tree. It stops with an error that wraps
+// ctx.Err() if ctx is done before rendering completes; the output up to that
+// point has been written to w.
+func RenderContext(ctx context.Context, node Node, w io.Writer, vars map[string]any, c Config) Error {
+	return renderNode(node, w, newNodeContext(ctx, vars, c))
+}
+
+// renderNode renders node with the evaluation context ctx. Nested renders,
+// such as {% include %}, use this in order to share the context.Context.
+func renderNode(node Node, w io.Writer, ctx nodeContext) Error {
+	return node.render(&limitWriter{w: w, limit: ctx.config.MaxOutputSize}, ctx)
 }
 
 // RenderSequence renders a sequence of nodes.
 func (c nodeContext) RenderSequence(w io.Writer, seq []Node) Error {
-	tw, ok := w.(*trimWriter)
+	lw, ok := w.(*limitWriter)
 	if !ok {
-		tw = &trimWriter{w: w}
+		lw = &limitWriter{w: w, limit: c.config.MaxOutputSize}
 	}
 	for _, n := range seq {
-		if err := n.render(tw, c); err != nil {
+		if err := n.render(lw, c); err != nil {
 			return err
 		}
 	}
-	if _, err := tw.Flush(); err != nil {
-		panic(err)
-	}
 	return nil
 }
 
-func (n *BlockNode) render(w *trimWriter, ctx nodeContext) Error {
+func (n *BlockNode) render(w *limitWriter, ctx nodeContext) (err Error) {
+	defer recoverTypeError(n, &err)
+	if err := ctx.checkDone(n); err != nil {
+		return err
+	}
 	cd, ok := ctx.config.findBlockDef(n.Name)
 	if !ok || cd.parser == nil {
 		// this should have been detected during compilation; it's an implementation error if it happens here
@@ -53,11 +55,10 @@ func (n *BlockNode) render(w *trimWriter, ctx nodeContext) Error {
 	if renderer == nil {
 		panic(fmt.Errorf("unset renderer for %v", n))
 	}
-	err := renderer(w, rendererContext{ctx, nil, n})
-	return wrapRenderError(err, n)
+	return wrapRenderError(renderer(w, rendererContext{ctx, nil, n}), n)
 }
 
-func (n *RawNode) render(w *trimWriter, ctx nodeContext) Error {
+func (n *RawNode) render(w *limitWriter, ctx nodeContext) Error {
 	for _, s := range n.slices {
 		_, err := io.WriteString(w, s)
 		if err != nil {
@@ -67,21 +68,18 @@ func (n *RawNode) render(w *trimWriter, ctx nodeContext) Error {
 	return nil
 }
 
-func (n *ObjectNode) render(w *trimWriter, ctx nodeContext) Error {
-	value, err := ctx.Evaluate(n.expr)
+func (n *ObjectNode) render(w *limitWriter, ctx nodeContext) Error {
+	value, err := ctx.Evaluate(n.expr, n)
 	if err != nil {
 		return wrapRenderError(err, n)
 	}
-	if value == nil && ctx.config.StrictVariables {
-		return wrapRenderError(errors.New("undefined variable"), n)
-	}
-	if err := wrapRenderError(writeObject(w, value), n); err != nil {
+	if err := wrapRenderError(WriteValue(w, value), n); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (n *SeqNode) render(w *trimWriter, ctx nodeContext) Error {
+func (n *SeqNode) render(w *limitWriter, ctx nodeContext) Error {
 	for _, c := range n.Children {
 		if err := c.render(w, ctx); err != nil {
 			return err
@@ -90,72 +88,26 @@ func (n *SeqNode) render(w *trimWriter, ctx nodeContext) Error {
 	return nil
 }
 
-func (n *TagNode) render(w *trimWriter, ctx nodeContext) Error {
-	err := wrapRenderError(n.renderer(w, rendererContext{ctx, n, nil}), n)
-	return err
+func (n *TagNode) render(w *limitWriter, ctx nodeContext) (err Error) {
+	defer recoverTypeError(n, &err)
+	if err := ctx.checkDone(n); err != nil {
+		return err
+	}
+	return wrapRenderError(n.renderer(w, rendererContext{ctx, n, nil}), n)
 }
 
-func (n *TextNode) render(w *trimWriter, _ nodeContext) Error {
-	_, err := io.WriteString(w, n.Source)
-	return wrapRenderError(err, n)
+func (n *TrimNode) render(*limitWriter, nodeContext) Error {
+	return nil
 }
 
-func (n *TrimNode) render(w *trimWriter, _ nodeContext) Error {
-	if n.TrimDirection == parser.Left {
-		return wrapRenderError(w.TrimLeft(), n)
-	} else {
-		w.TrimRight()
-		return nil
-	}
+func (n *TextNode) render(w *limitWriter, _ nodeContext) Error {
+	_, err := io.WriteString(w, n.Source)
+	return wrapRenderError(err, n)
 }
 
-// writeObject writes a value used in an object node
-func writeObject(w io.Writer, value any) error {
-	value = values.ToLiquid(value)
-	if value == nil {
-		return nil
-	}
-	switch value := value.(type) {
-	case time.Time:
-		_, err := io.WriteString(w, value.Format("2006-01-02 15:04:05 -0700"))
-		return err
-	case []byte:
-		_, err := w.Write(value)
-		return err
-		// there used be a case on fmt.Stringer here, but fmt.Sprint produces better results than obj.Write
-		// for instances of error and *string
-	case float64:
-		strVal := strconv.FormatFloat(value, 'f', -1, 64)
-
-		// very debatable whether we should add the .0.
-		// If we don't, {{ 123.00 }} will render as "123"
-		// but in official liquid it's "123.0"
-		// not doing it for now. Might change it later.
-		// if !strings.Contains(strVal, ".") {
-		// 	strVal = strVal + ".0"
-		// }
-		_, err := io.WriteString(w, strVal)
-		return err
-	case float32:
-		_, err := io.WriteString(w, strconv.FormatFloat(float64(value), 'f', -1, 64))
-		return err
-	}
-	rt := reflect.ValueOf(value)
-	switch rt.Kind() {
-	case reflect.Array, reflect.Slice:
-		for i := range rt.Len() {
-			item := rt.Index(i)
-			if item.IsValid() {
-				if err := writeObject(w, item.Interface()); err != nil {
-					return err
-				}
-			}
-		}
-		return nil
-	case reflect.Ptr:
-		return writeObject(w, reflect.ValueOf(value).Elem())
-	default:
-		_, err := io.WriteString(w, fmt.Sprint(value))
-		return err
-	}
+// WriteValue writes a value as an object {{ value }} renders it. It is also
+// used in the implementation of the {% echo %} tag. See values.Format.
+func WriteValue(w io.Writer, value any) error {
+	_, err := io.WriteString(w, values.Format(value))
+	return err
 }
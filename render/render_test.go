@@ -11,6 +11,7 @@ import (
 	e "github.com/osteele/liquid/expressions"
 
 	"github.com/osteele/liquid/parser"
+	"github.com/osteele/liquid/values"
 	"github.com/stretchr/testify/require"
 )
 
@@ -63,6 +64,11 @@ var renderTests = []struct{ in, out string }{
 	{"x\n{%- if true -%}\ny\n{% endif %}\nz", "xy\n\nz"},
 	{"x\n{%- if true -%}\ny\n{%- endif %}\nz", "xy\nz"},
 	{"x\n{%- if true -%}\ny\n{%- endif -%}\nz", "xyz"},
+	{"x\n\n \t{%- y -%}\n\n \tz", "xyz"},
+	{`{{ "x " -}}{{ " z" }}`, "x  z"},
+	{`{{ "x " }}{{- " z" }}`, "x  z"},
+	{`{{ 1 -}}{{ 2 }} z`, "12 z"},
+	{`{{ 1 -}}{% null %} z`, "1 z"},
 }
 
 var renderStrictTests = []struct{ in, out string }{
@@ -84,6 +90,9 @@ var renderStrictTests = []struct{ in, out string }{
 
 var renderErrorTests = []struct{ in, out string }{
 	{`{% errblock %}{% enderrblock %}`, "errblock error"},
+	{`{% int_tag x %}`, "can't convert string(x) to type int"},
+	// line numbers are zero-based, since the tests use a zero SourceLoc
+	{"{% if true %}\n{% int_tag x %}{% endif %}", "Liquid error (line 1): can't convert"},
 }
 
 var renderTestBindings = map[string]any{
@@ -171,6 +180,12 @@ func addRenderTestTags(cfg Config) {
 	cfg.AddTag("null", func(string) (func(io.Writer, Context) error, error) {
 		return func(io.Writer, Context) error { return nil }, nil
 	})
+	cfg.AddTag("int_tag", func(arg string) (func(io.Writer, Context) error, error) {
+		return func(w io.Writer, _ Context) error {
+			_, err := fmt.Fprint(w, values.ValueOf(arg).Int())
+			return err
+		}, nil
+	})
 	cfg.AddBlock("errblock").Compiler(func(c BlockNode) (func(io.Writer, Context) error, error) {
 		return func(w io.Writer, c Context) error {
 			return errors.New("errblock error")
@@ -2,6 +2,7 @@ package render
 
 import (
 	"io"
+	"sort"
 )
 
 // TagCompiler is a function that parses the tag arguments, and returns a renderer.
@@ -18,3 +19,13 @@ func (c *Config) FindTagDefinition(name string) (TagCompiler, bool) {
 	td, ok := c.tags[name]
 	return td, ok
 }
+
+// TagNames returns the sorted names of the tags that have been added with AddTag.
+func (c *Config) TagNames() []string {
+	names := make([]string, 0, len(c.tags))
+	for name := range c.tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
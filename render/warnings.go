@@ -0,0 +1,53 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/osteele/liquid/parser"
+)
+
+// A Warning records a problem that doesn't stop the render, such as a reference
+// to an undefined variable.
+type Warning struct {
+	Message string
+	Path    string
+	Line    int
+	Column  int
+}
+
+func newWarning(err error, loc parser.Locatable) Warning {
+	sl := loc.SourceLocation()
+	return Warning{err.Error(), sl.Pathname, sl.LineNo, sl.ColNo}
+}
+
+func (w Warning) String() string {
+	sl := parser.SourceLoc{Pathname: w.Path, LineNo: w.Line, ColNo: w.Column}
+	return fmt.Sprintf("%s: %s", sl, w.Message)
+}
+
+// RenderWarnings is like RenderContext, but it also returns warnings for
+// references to undefined variables and properties, and for applications of
+// undefined filters. An undefined filter evaluates to its input, instead of
+// stopping the render.
+func RenderWarnings(ctx context.Context, node Node, w io.Writer, vars map[string]any, c Config) ([]Warning, Error) {
+	warnings := []Warning{}
+	nc := newNodeContext(ctx, vars, c)
+	nc.warnings = &warnings
+	err := renderNode(node, w, nc)
+	return uniqueWarnings(warnings), err
+}
+
+// uniqueWarnings removes repeats, such as from the body of a loop, of a warning.
+func uniqueWarnings(warnings []Warning) []Warning {
+	seen := map[Warning]bool{}
+	out := warnings[:0]
+	for _, w := range warnings {
+		if !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	return out
+}
@@ -119,3 +119,28 @@ func ifTagCompiler(polarity bool) func(render.BlockNode) (func(io.Writer, render
 		}, nil
 	}
 }
+
+// ifchangedVarName holds the last output of each ifchanged block.
+const ifchangedVarName = ".ifchanged"
+
+func ifchangedTagCompiler(node render.BlockNode) (func(io.Writer, render.Context) error, error) {
+	// identifies this block, among the blocks that share the render context
+	key := new(int)
+	return func(w io.Writer, ctx render.Context) error {
+		s, err := ctx.InnerString()
+		if err != nil {
+			return err
+		}
+		last, _ := ctx.Get(ifchangedVarName).(map[*int]string)
+		if last == nil {
+			last = map[*int]string{}
+			ctx.Set(ifchangedVarName, last)
+		}
+		if prev, seen := last[key]; seen && prev == s {
+			return nil
+		}
+		last[key] = s
+		_, err = io.WriteString(w, s)
+		return err
+	}, nil
+}
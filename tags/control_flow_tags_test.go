@@ -24,6 +24,12 @@ var cfTagTests = []struct{ in, expected string }{
 	{`{% case 1 %}{% when 1,2 %}a{% else %}b{% endcase %}`, "a"},
 	{`{% case 2 %}{% when 1,2 %}a{% else %}b{% endcase %}`, "a"},
 	{`{% case 3 %}{% when 1,2 %}a{% else %}b{% endcase %}`, "b"},
+	{`{% case "b" %}{% when "a", "b" %}a{% else %}b{% endcase %}`, "a"},
+	{`{% case 2 %}{% when 1 or 2 %}a{% else %}b{% endcase %}`, "a"},
+	{`{% case 3 %}{% when 1 or 2 %}a{% else %}b{% endcase %}`, "b"},
+	{`{% case 3 %}{% when 1 or 2, 3 %}a{% else %}b{% endcase %}`, "a"},
+	{`{% case x %}{% when 1 or 123 %}a{% when 123 %}b{% endcase %}`, "a"},
+	{`{% case "c" %}{% when "a" or "b" %}a{% when "c" or "d" %}c{% else %}e{% endcase %}`, "c"},
 
 	// if
 	{`{% if true %}true{% endif %}`, "true"},
@@ -40,16 +46,41 @@ var cfTagTests = []struct{ in, expected string }{
 	{`{% if false %}0{% elsif true %}1{% else %}2{% endif %}`, "1"},
 	{`{% if false %}0{% elsif false %}1{% else %}2{% endif %}`, "2"},
 	{`{% if 2456789.01 > 2456789 %}true{% endif %}`, "true"},
+	{`{% if true or false and false %}true{% else %}false{% endif %}`, "true"},
+	{`{% if (true or false) and false %}true{% else %}false{% endif %}`, "false"},
+	{`{% if (x == 1 or x == 123) and obj.a == 1 %}true{% endif %}`, "true"},
+	{`{% if not x == 1 %}true{% endif %}`, "true"},
+	{`{% if false %}0{% elsif not (x > 100) %}1{% else %}2{% endif %}`, "2"},
+	{`{% assign not = true %}{% if not %}true{% endif %}`, "true"},
+	{`{% assign not = 1 %}{% if not == 1 %}{{ not }}{% endif %}`, "1"},
 
 	// unless
 	{`{% unless true %}false{% endunless %}`, ""},
 	{`{% unless false %}true{% endunless %}`, "true"},
 	{`{% unless true %}true{% else %}false{% endunless %}`, "false"},
+	{`{% unless false %}0{% elsif true %}1{% else %}2{% endunless %}`, "0"},
+	{`{% unless true %}0{% elsif true %}1{% else %}2{% endunless %}`, "1"},
+	{`{% unless true %}0{% elsif false %}1{% else %}2{% endunless %}`, "2"},
+	{`{% unless true %}0{% elsif false %}1{% elsif true %}2{% else %}3{% endunless %}`, "2"},
+	{`{% unless true %}0{% elsif false %}1{% endunless %}`, ""},
+	{`{% unless not x %}true{% endunless %}`, "true"},
+	{`{% unless x %}0{% elsif x == 123 %}1{% else %}2{% endunless %}`, "1"},
+	{`{% unless x %}0{% elsif x == 1 %}1{% else %}2{% endunless %}`, "2"},
+	{`{% unless x == 1 %}0{% elsif x == 123 %}1{% else %}2{% endunless %}`, "0"},
+
+	// ifchanged
+	{`{% ifchanged %}a{% endifchanged %}`, "a"},
+	{`{% for n in repeats %}{% ifchanged %}{{ n }}{% endifchanged %}.{% endfor %}`, "1..2.3..1."},
+	{`{% for n in repeats %}{% ifchanged %}{{ n }}{% endifchanged %}{% ifchanged %}{% if n > 1 %}big{% endif %}{% endifchanged %}.{% endfor %}`, "1..2big.3..1."},
+	{`{% for n in repeats %}{% ifchanged %}{% if n == 2 %}two{% endif %}{% endifchanged %}.{% endfor %}`, "..two...."},
+	{`{% for n in repeats %}{% ifchanged %}{% if n == 1 %}one{% endif %}{% endifchanged %}.{% endfor %}`, "one.....one."},
+	{`{% for n in repeats %}{% ifchanged %}{{ n }}{% endifchanged %}{% endfor %}{% ifchanged %}1{% endifchanged %}`, "12311"},
 }
 
 var cfTagCompilationErrorTests = []struct{ in, expected string }{
 	{`{% if syntax error %}{% endif %}`, "syntax error"},
 	{`{% if true %}{% elsif syntax error %}{% endif %}`, "syntax error"},
+	{`{% unless true %}{% elsif syntax error %}{% endunless %}`, "syntax error"},
 	{`{% case syntax error %}{% when 1 %}{% endcase %}`, "syntax error"},
 }
 
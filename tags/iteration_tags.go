@@ -7,17 +7,29 @@ import (
 	"math"
 	"reflect"
 	"sort"
+	"strings"
 
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/osteele/liquid/expressions"
 	"github.com/osteele/liquid/render"
+	"github.com/osteele/liquid/values"
 )
 
 // An IterationKeyedMap is a map that yields its keys, instead of (key, value) pairs, when iterated.
 type IterationKeyedMap map[string]any
 
-const forloopVarName = "forloop"
+const (
+	forloopVarName      = "forloop"
+	tablerowloopVarName = "tablerowloop"
+)
+
+// cyclesVarName holds the cursors of the cycle tags, for the duration of a render.
+const cyclesVarName = ".cycles"
+
+// loopOffsetsVarName holds the positions that offset: continue resumes from.
+// The leading dot keeps it out of reach of template expressions.
+const loopOffsetsVarName = ".loop_offsets"
 
 var (
 	errLoopContinueLoop = errors.New("continue outside a loop")
@@ -47,29 +59,37 @@ func cycleTag(args string) (func(io.Writer, render.Context) error, error) {
 		return nil, err
 	}
 	cycle := stmt.Cycle
+	// Cycles in the same named group share a cursor. Unnamed cycles share a
+	// cursor with those that have the same values.
+	key := cycleKey{group: cycle.Group}
+	if cycle.Group == "" {
+		key.values = fmt.Sprintf("%q", cycle.Values)
+	}
 	return func(w io.Writer, ctx render.Context) error {
-		loopVar := ctx.Get(forloopVarName)
-		if loopVar == nil {
+		if ctx.Get(forloopVarName) == nil {
 			return ctx.Errorf("cycle must be within a forloop")
 		}
-		// The next few lines could panic if the user spoofs us by creating their own loop object.
-		// “C++ protects against accident, not against fraud.” – Bjarne Stroustrup
-		loopRec := loopVar.(map[string]any)
-		cycleMap := loopRec[".cycles"].(map[string]int)
-		group, values := cycle.Group, cycle.Values
-		n := cycleMap[group]
-		cycleMap[group] = n + 1
+		cursors, _ := ctx.Get(cyclesVarName).(map[cycleKey]int)
+		if cursors == nil {
+			cursors = map[cycleKey]int{}
+			ctx.Set(cyclesVarName, cursors)
+		}
+		n := cursors[key]
+		cursors[key] = n + 1
 		// The parser guarantees that there will be at least one item.
-		_, err = io.WriteString(w, values[n%len(values)])
+		_, err := io.WriteString(w, cycle.Values[n%len(cycle.Values)])
 		return err
 	}, nil
 }
 
+type cycleKey struct{ group, values string }
+
 func loopTagCompiler(node render.BlockNode) (func(io.Writer, render.Context) error, error) {
 	stmt, err := expressions.ParseStatement(expressions.LoopStatementSelector, node.Args)
 	if err != nil {
 		return nil, err
 	}
+	loopName := loopOffsetKey(node.Args)
 
 	return func(w io.Writer, ctx render.Context) error {
 		// loop modifiers
@@ -83,7 +103,7 @@ func loopTagCompiler(node render.BlockNode) (func(io.Writer, render.Context) err
 			return nil
 		}
 
-		iter, err = applyLoopModifiers(stmt.Loop, ctx, iter)
+		iter, err = applyLoopModifiers(stmt.Loop, loopName, ctx, iter)
 		if err != nil {
 			return err
 		}
@@ -117,20 +137,21 @@ func (loop loopRenderer) render(iter iterable, w io.Writer, ctx render.Context)
 		ctx.Set(forloopVarName, index)
 		ctx.Set(loop.Variable, forloop)
 	}(ctx.Get(forloopVarName), ctx.Get(loop.Variable))
-	cycleMap := map[string]int{}
+	// the enclosing loop's forloop, or nil; each level links to the one above it
+	parentloop := ctx.Get(forloopVarName)
+	tableCols, isTableRow := decorator.(tableRowDecorator)
+	if isTableRow {
+		defer func(tablerowloop any) {
+			ctx.Set(tablerowloopVarName, tablerowloop)
+		}(ctx.Get(tablerowloopVarName))
+	}
 loop:
 	for i, l := 0, iter.Len(); i < l; i++ {
 		ctx.Set(loop.Variable, iter.Index(i))
-		ctx.Set(forloopVarName, map[string]any{
-			"first":   i == 0,
-			"last":    i == l-1,
-			"index":   i + 1,
-			"index0":  i,
-			"rindex":  l - i,
-			"rindex0": l - i - 1,
-			"length":  l,
-			".cycles": cycleMap,
-		})
+		ctx.Set(forloopVarName, forloopObject(i, l, parentloop))
+		if isTableRow {
+			ctx.Set(tablerowloopVarName, tableRowLoop(i, l, int(tableCols)))
+		}
 		decorator.before(w, i)
 		err := ctx.RenderChildren(w)
 		decorator.after(w, i, l)
@@ -148,6 +169,20 @@ loop:
 	return nil
 }
 
+// forloopObject returns the forloop object for the i'th of l items.
+func forloopObject(i, l int, parentloop any) map[string]any {
+	return map[string]any{
+		"first":      i == 0,
+		"last":       i == l-1,
+		"index":      i + 1,
+		"index0":     i,
+		"rindex":     l - i,
+		"rindex0":    l - i - 1,
+		"length":     l,
+		"parentloop": parentloop,
+	}
+}
+
 func makeLoopDecorator(loop loopRenderer, ctx render.Context) (loopDecorator, error) {
 	if loop.tagName == "tablerow" {
 		if loop.Cols != nil {
@@ -178,6 +213,28 @@ type forLoopDecorator struct{}
 func (d forLoopDecorator) before(io.Writer, int)     {}
 func (d forLoopDecorator) after(io.Writer, int, int) {}
 
+// tableRowLoop returns the tablerowloop object for the i'th of l items.
+func tableRowLoop(i, l, cols int) map[string]any {
+	if cols > l {
+		cols = l
+	}
+	col0 := i % cols
+	return map[string]any{
+		"first":     i == 0,
+		"last":      i == l-1,
+		"index":     i + 1,
+		"index0":    i,
+		"rindex":    l - i,
+		"rindex0":   l - i - 1,
+		"length":    l,
+		"col":       col0 + 1,
+		"col0":      col0,
+		"col_first": col0 == 0,
+		"col_last":  col0 == cols-1,
+		"row":       i/cols + 1,
+	}
+}
+
 type tableRowDecorator int
 
 func (c tableRowDecorator) before(w io.Writer, i int) {
@@ -205,23 +262,43 @@ func (c tableRowDecorator) after(w io.Writer, i, l int) {
 	}
 }
 
-func applyLoopModifiers(loop expressions.Loop, ctx render.Context, iter iterable) (iterable, error) {
-	if loop.Reversed {
-		iter = reverseWrapper{iter}
+// loopOffsetKey returns the key under which offset: continue records the
+// position of a loop. As in Shopify Liquid, this is the loop variable and
+// the source text of the collection.
+func loopOffsetKey(args string) string {
+	fields := strings.Fields(args)
+	if len(fields) < 3 {
+		return args
+	}
+	return fields[0] + "-" + fields[2]
+}
+
+// applyLoopModifiers applies the offset and limit modifiers, and then reversed.
+// It records where the loop stops, for a later loop with offset: continue.
+func applyLoopModifiers(loop expressions.Loop, name string, ctx render.Context, iter iterable) (iterable, error) {
+	offsets, _ := ctx.Get(loopOffsetsVarName).(map[string]int)
+	if offsets == nil {
+		offsets = map[string]int{}
+		ctx.Set(loopOffsetsVarName, offsets)
 	}
 
-	if loop.Offset != nil {
+	offset := 0
+	switch {
+	case loop.OffsetContinue:
+		offset = offsets[name]
+	case loop.Offset != nil:
 		val, err := ctx.Evaluate(loop.Offset)
 		if err != nil {
 			return nil, err
 		}
-		offset, ok := val.(int)
+		n, ok := val.(int)
 		if !ok {
 			return nil, ctx.Errorf("loop offset must be an integer")
 		}
-		if offset > 0 {
-			iter = offsetWrapper{iter, offset}
-		}
+		offset = n
+	}
+	if offset > 0 {
+		iter = offsetWrapper{iter, offset}
 	}
 
 	if loop.Limit != nil {
@@ -238,6 +315,12 @@ func applyLoopModifiers(loop expressions.Loop, ctx render.Context, iter iterable
 		}
 	}
 
+	offsets[name] = intMax(offset, 0) + iter.Len()
+
+	if loop.Reversed {
+		iter = reverseWrapper{iter}
+	}
+
 	return iter, nil
 }
 
@@ -259,17 +342,113 @@ func makeIterator(value any) iterable {
 		return sliceWrapper(reflect.ValueOf(value))
 	case reflect.Map:
 		rv := reflect.ValueOf(value)
-		array := make([][]any, rv.Len())
-		for i, k := range rv.MapKeys() {
+		keys := sortedMapKeys(rv)
+		array := make([][]any, len(keys))
+		for i, k := range keys {
 			v := rv.MapIndex(k)
 			array[i] = []any{k.Interface(), v.Interface()}
 		}
 		return sliceWrapper(reflect.ValueOf(array))
+	case reflect.Struct:
+		return sliceWrapper(reflect.ValueOf(structFieldPairs(reflect.ValueOf(value))))
+	case reflect.Ptr:
+		rv := reflect.ValueOf(value)
+		if rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+			return nil
+		}
+		return sliceWrapper(reflect.ValueOf(structFieldPairs(rv.Elem())))
 	default:
 		return nil
 	}
 }
 
+// sortedMapKeys returns the keys of a map in a deterministic order: numerically if
+// all the keys are numbers, else lexicographically by their string representation.
+func sortedMapKeys(rv reflect.Value) []reflect.Value {
+	keys := rv.MapKeys()
+	numeric := true
+	for _, k := range keys {
+		if k.Kind() == reflect.Interface {
+			k = k.Elem()
+		}
+		if !isNumberKind(k.Kind()) {
+			numeric = false
+			break
+		}
+	}
+	if numeric {
+		sort.Slice(keys, func(i, j int) bool {
+			return values.Less(keys[i].Interface(), keys[j].Interface())
+		})
+		return keys
+	}
+	strs := make([]string, len(keys))
+	for i, k := range keys {
+		strs[i] = fmt.Sprint(k.Interface())
+	}
+	sort.Sort(keysByString{keys, strs})
+	return keys
+}
+
+type keysByString struct {
+	keys []reflect.Value
+	strs []string
+}
+
+func (s keysByString) Len() int           { return len(s.keys) }
+func (s keysByString) Less(i, j int) bool { return s.strs[i] < s.strs[j] }
+func (s keysByString) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.strs[i], s.strs[j] = s.strs[j], s.strs[i]
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// structFieldPairs returns [name, value] pairs for the exported fields of a struct,
+// in declaration order. Fields of embedded structs are flattened into the result.
+// Like property access, it uses the name from a `liquid:"name"` tag if present,
+// and skips fields tagged `liquid:"-"`.
+func structFieldPairs(rv reflect.Value) (pairs [][]any) {
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+		if field.Anonymous {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				pairs = append(pairs, structFieldPairs(fv)...)
+				continue
+			}
+		}
+		if !field.IsExported() || fv.Kind() == reflect.Func {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("liquid"); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		pairs = append(pairs, []any{name, fv.Interface()})
+	}
+	return pairs
+}
+
 func makeIterationKeyedMap(m map[string]any) iterable {
 	// Iteration chooses a random start, so we need a copy of the keys to iterate through them.
 	keys := make([]string, 0, len(m))
@@ -16,6 +16,11 @@ import (
 )
 
 var iterationTests = []struct{ in, expected string }{
+	// assign and capture don't change the loop state
+	{`{% for a in array %}{% assign forloop = 5 %}{{ forloop.index }}{% endfor %}`, "123"},
+	{`{% for a in array %}{% capture forloop %}x{% endcapture %}{{ forloop.last }},{% endfor %}`, "false,false,true,"},
+	{`{% for a in array %}{% assign forloop, b = array %}{{ forloop.index }}{{ b }},{% endfor %}`, "1second,2second,3second,"},
+	{`{% tablerow a in array %}{% assign tablerowloop = 5 %}{{ tablerowloop.col }}{% endtablerow %}`, `<tr class="row1"><td class="col1">1</td><td class="col2">2</td><td class="col3">3</td></tr>`},
 	{`{% for a in array %}{{ a }} {% endfor %}`, "first second third "},
 	{`{% for a in array %}{{ a }} {% else %}else{% endfor %}`, "first second third "},
 	{`{% for a in nil %}{{ a }}.{% endfor %}`, ""},
@@ -23,8 +28,22 @@ var iterationTests = []struct{ in, expected string }{
 	{`{% for a in 2 %}{{ a }}.{% endfor %}`, ""},
 	{`{% for a in "str" %}{{ a }}.{% endfor %}`, ""},
 	{`{% for a in map %}{{ a[0] }}={{ a[1] }}.{% endfor %}`, "a=1."},
+	{`{% for a in string_map %}{{ a[0] }}={{ a[1] }}.{% endfor %}`, "10=x.9=y.B=z.a=w."},
+	{`{% for a in int_map %}{{ a[0] }}={{ a[1] }}.{% endfor %}`, "-1=w.2=x.10=y.100=z."},
+	{`{% for a in any_map %}{{ a[0] }}.{% endfor %}`, "1.1.5.2."},
 	{`{% for a in map_slice %}{{ a[0] }}={{ a[1] }}.{% endfor %}`, "a=1.b=2."},
 	{`{% for k in keyed_map %}{{ k }}={{ keyed_map[k] }}.{% endfor %}`, "a=1.b=2."},
+	{`{% for a in struct %}{{ a[0] }}={{ a[1] }}.{% endfor %}`, "Host=localhost.port=80.Debug=true.Name=config."},
+	{`{% for a in struct_ptr %}{{ a.first }}={{ a.last }}.{% endfor %}`, "Host=localhost.port=80.Debug=true.Name=config."},
+	{`{% for a in empty_struct %}{{ a }}.{% else %}empty{% endfor %}`, "empty"},
+	{`{% for a in nil_struct_ptr %}{{ a }}.{% endfor %}`, ""},
+
+	// whitespace control
+	{"{% for a in array -%}\n  {{ a }}\n{%- endfor %}", "firstsecondthird"},
+	{"<ul>\n{%- for a in array %}\n  <li>{{ a }}</li>\n{%- endfor %}\n</ul>", "<ul>\n  <li>first</li>\n  <li>second</li>\n  <li>third</li>\n</ul>"},
+	{"{% for a in array %}\n  {{- a -}}\n{% endfor %}", "firstsecondthird"},
+	{"{% for a in array -%} \n\t{{ a }}, {% else -%} \n empty {%- endfor %}", "first, second, third, "},
+	{"{% for a in empty_struct -%} \n\t{{ a }}, {% else -%} \n empty {%- endfor %}.", "empty."},
 
 	// loop modifiers
 	{`{% for a in array reversed %}{{ a }}.{% endfor %}`, "third.second.first."},
@@ -36,15 +55,35 @@ var iterationTests = []struct{ in, expected string }{
 	{`{% for a in array offset: offset %}{{ a }}.{% endfor %}`, "second.third."},
 	{`{% for a in array offset: loopmods.offset %}{{ a }}.{% endfor %}`, "second.third."},
 	{`{% for a in array offset: loopmods["offset"] %}{{ a }}.{% endfor %}`, "second.third."},
-	{`{% for a in array reversed limit: 1 %}{{ a }}.{% endfor %}`, "third."},
+	{`{% for a in array reversed limit: 1 %}{{ a }}.{% endfor %}`, "first."},
 	{`{% for a in array limit: 0 %}{{ a }}.{% endfor %}`, ""},
 	{`{% for a in array limit: 0 %}{{ a }}.{% else %}ELSE{% endfor %}`, "ELSE"},
 	{`{% for a in array offset: 3 %}{{ a }}.{% endfor %}`, ""},
 	{`{% for a in array offset: 10 %}{{ a }}.{% endfor %}`, ""},
-	// TODO investigate how these combine; does it depend on the order?
-	// {`{% for a in array reversed offset:1 %}{{ a }}.{% endfor %}`, "second.first."},
-	// {`{% for a in array limit:1 offset:1 %}{{ a }}.{% endfor %}`, "second."},
-	// {`{% for a in array reversed limit:1 offset:1 %}{{ a }}.{% endfor %}`, "second."},
+	// as in Shopify Liquid, offset and limit apply before reversed
+	{`{% for a in array reversed offset:1 %}{{ a }}.{% endfor %}`, "third.second."},
+	{`{% for a in array limit:1 offset:1 %}{{ a }}.{% endfor %}`, "second."},
+	{`{% for a in array reversed limit:1 offset:1 %}{{ a }}.{% endfor %}`, "second."},
+	{`{% for a in array reversed limit:2 %}{{ a }}.{% endfor %}`, "second.first."},
+
+	// offset: continue
+	{`{% for a in array limit:2 %}{{ a }}.{% endfor %}{% for a in array offset:continue %}{{ a }}.{% endfor %}`, "first.second.third."},
+	{`{% for a in array limit:1 %}{{ a }}.{% endfor %}{% for a in array limit:1 offset:continue %}{{ a }}.{% endfor %}{% for a in array limit:1 offset: continue %}{{ a }}.{% endfor %}`, "first.second.third."},
+	{`{% for a in array offset:continue limit:2 %}{{ a }}.{% endfor %}`, "first.second."},
+	{`{% for a in array offset:1 limit:1 %}{{ a }}.{% endfor %};{% for a in array offset:continue %}{{ a }}.{% endfor %}`, "second.;third."},
+	{`{% for a in array limit:2 %}{% endfor %}{% for a in array reversed offset:continue %}{{ a }}.{% endfor %}`, "third."},
+	{`{% for a in array %}{% endfor %}{% for a in array offset:continue %}{{ a }}.{% else %}done{% endfor %}`, "done"},
+	{`{% for a in array limit:2 %}{% endfor %}{% for b in array offset:continue %}{{ b }}.{% endfor %}`, "first.second.third."},
+	{`{% for a in array limit:2 %}{% endfor %}{% for a in products offset:continue limit:1 %}{{ a }}.{% endfor %}`, "Cool Shirt."},
+	{`{% for continue in array limit:1 %}{{ continue }}.{% endfor %}`, "first."},
+
+	// forloop.parentloop
+	{`{% for a in array %}{% if forloop.parentloop %}parent{% else %}none{% endif %}.{% endfor %}`, "none.none.none."},
+	{`{% for a in array limit:2 %}{% for b in array limit:2 %}{{ forloop.parentloop.index }}-{{ forloop.index }}.{% endfor %}{% endfor %}`, "1-1.1-2.2-1.2-2."},
+	{`{% for a in array limit:2 %}{% for b in array limit:1 %}{% for c in array limit:2 %}{{ forloop.parentloop.parentloop.index }}{{ forloop.parentloop.index }}{{ forloop.index }}.{% endfor %}{% endfor %}{% endfor %}`, "111.112.211.212."},
+	{`{% for a in array limit:1 %}{% for b in array limit:1 %}{% for c in array limit:1 %}{{ forloop.parentloop.parentloop.parentloop }}{% endfor %}{% endfor %}{% endfor %}`, ""},
+	{`{% for a in array limit:2 %}{% for b in array limit:1 %}{% endfor %}{{ forloop.parentloop }}{{ forloop.index }}.{% endfor %}`, "1.2."},
+	{`{% for a in array limit:2 %}{% for b in array limit:1 %}{{ forloop.parentloop.last }}.{% endfor %}{% endfor %}`, "false.true."},
 
 	// loop variables
 	{`{% for a in array %}{{ forloop.first }}.{% endfor %}`, "true.false.false."},
@@ -84,12 +123,27 @@ var iterationTests = []struct{ in, expected string }{
 	// cycle
 	{`{% for a in array %}{% cycle 'even', 'odd' %}.{% endfor %}`, "even.odd.even."},
 	{`{% for a in array %}{% cycle '0', '1' %},{% cycle '0', '1' %}.{% endfor %}`, "0,1.0,1.0,1."},
-	// {`{% for a in array %}{% cycle group: 'a', '0', '1' %},{% cycle '0', '1' %}.{% endfor %}`, "0,1.0,1.0,1."},
+	{`{% for a in array %}{% cycle 'a', 'b' %}{% cycle 'x', 'y', 'z' %}.{% endfor %}`, "ax.by.az."},
+	{`{% for a in array %}{% cycle 'a', 'b' %}{% endfor %}{% for a in array %}{% cycle 'a', 'b' %}{% endfor %}`, "ababab"},
+	{`{% for a in array %}{% cycle 'g': 'a', 'b', 'c' %}.{% endfor %}`, "a.b.c."},
+	{`{% for a in array %}{% cycle 'g': 'a', 'b' %},{% cycle 'g': '0', '1' %}.{% endfor %}`, "a,1.a,1.a,1."},
+	{`{% for a in array %}{% cycle 'g': '0', '1' %},{% cycle '0', '1' %}.{% endfor %}`, "0,0.1,1.0,0."},
+	{`{% for a in array %}{% cycle 'g1': '0', '1' %},{% cycle 'g2': '0', '1' %}.{% endfor %}`, "0,0.1,1.0,0."},
 
 	// range
 	{`{% for i in (3 .. 5) %}{{i}}.{% endfor %}`, "3.4.5."},
 	{`{% for i in (3..5) %}{{i}}.{% endfor %}`, "3.4.5."},
 	{`{% assign l = (3..5) %}{% for i in l %}{{i}}.{% endfor %}`, "3.4.5."},
+	{`{% for i in (offset..limit) %}{{i}}.{% endfor %}`, "1.2."},
+	{`{% for i in (loopmods.offset..cols) %}{{i}}.{% endfor %}`, "1.2."},
+	{`{% for i in (limit..limit) %}{{i}}.{% endfor %}`, "2."},
+	{`{% for i in ("2".."4") %}{{i}}.{% endfor %}`, "2.3.4."},
+	{`{% for i in (-1..1) %}{{i}}.{% endfor %}`, "-1.0.1."},
+	// as in Shopify, a range whose start is after its end is empty
+	{`{% for i in (5..3) %}{{i}}.{% else %}empty{% endfor %}`, "empty"},
+	{`{% for i in (limit..offset) %}{{i}}.{% endfor %}`, ""},
+	{`{% for i in (5..3) reversed %}{{i}}.{% endfor %}`, ""},
+	{`{% for i in (3..5) reversed %}{{i}}.{% endfor %}`, "5.4.3."},
 
 	// tablerow
 	{
@@ -126,6 +180,32 @@ var iterationTests = []struct{ in, expected string }{
 		 <tr class="row2"><td class="col1">Batman Poster</td><td class="col2">Bullseye Shirt</td></tr>
 		 <tr class="row3"><td class="col1">Another Classic Vinyl</td><td class="col2">Awesome Jeans</td></tr>`,
 	},
+	{
+		`{% tablerow product in products limit:5 cols:2 %}{{ product }}{% endtablerow %}`,
+		`<tr class="row1"><td class="col1">Cool Shirt</td><td class="col2">Alien Poster</td></tr>
+		 <tr class="row2"><td class="col1">Batman Poster</td><td class="col2">Bullseye Shirt</td></tr>
+		 <tr class="row3"><td class="col1">Another Classic Vinyl</td></tr>`,
+	},
+	{
+		`{% tablerow product in products offset:2 limit:3 cols:2 %}{{ product }}{% endtablerow %}`,
+		`<tr class="row1"><td class="col1">Batman Poster</td><td class="col2">Bullseye Shirt</td></tr>
+		 <tr class="row2"><td class="col1">Another Classic Vinyl</td></tr>`,
+	},
+	{
+		`{% tablerow product in products limit:5 cols:2 %}{{ tablerowloop.row }}.{{ tablerowloop.col }}.{{ tablerowloop.col0 }}.{{ tablerowloop.index }}{% endtablerow %}`,
+		`<tr class="row1"><td class="col1">1.1.0.1</td><td class="col2">1.2.1.2</td></tr>
+		 <tr class="row2"><td class="col1">2.1.0.3</td><td class="col2">2.2.1.4</td></tr>
+		 <tr class="row3"><td class="col1">3.1.0.5</td></tr>`,
+	},
+	{
+		`{% tablerow product in products limit:3 cols:2 %}{{ tablerowloop.col_first }}.{{ tablerowloop.col_last }}.{{ tablerowloop.first }}.{{ tablerowloop.last }}{% endtablerow %}`,
+		`<tr class="row1"><td class="col1">true.false.true.false</td><td class="col2">false.true.false.false</td></tr>
+		 <tr class="row2"><td class="col1">true.false.false.true</td></tr>`,
+	},
+	{
+		`{% tablerow product in products limit:2 %}{{ tablerowloop.length }}.{{ tablerowloop.rindex }}.{{ tablerowloop.rindex0 }}.{{ tablerowloop.index0 }}.{{ tablerowloop.col_last }}{% endtablerow %}{{ tablerowloop }}`,
+		`<tr class="row1"><td class="col1">2.2.1.0.false</td><td class="col2">2.1.0.1.true</td></tr>`,
+	},
 }
 
 var iterationSyntaxErrorTests = []struct{ in, expected string }{
@@ -141,14 +221,46 @@ var iterationErrorTests = []struct{ in, expected string }{
 	{`{% for a in array | undefined_filter %}{% endfor %}`, "undefined filter"},
 	{`{% for a in array %}{{ a | undefined_filter }}{% endfor %}`, "undefined filter"},
 	{`{% for a in array %}{% else %}{% else %}{% endfor %}`, "for loops accept at most one else clause"},
+	{`{% for i in (1.5..3) %}{% endfor %}`, "invalid range bound 1.5; expected an integer"},
+	{`{% for i in (1..array) %}{% endfor %}`, "invalid range bound []string"},
+	{`{% for i in ("a"..3) %}{% endfor %}`, `invalid range bound "a"`},
+	{`{% for i in (1..undefined) %}{% endfor %}`, "invalid range bound <nil>"},
+}
+
+type iterationTestEmbedded struct {
+	Debug bool
+	Name  string
+}
+
+type iterationTestStruct struct {
+	Host string
+	Port int `liquid:"port"`
+	iterationTestEmbedded
+	Secret   string `liquid:"-"`
+	internal string
+	Callback func() string
+}
+
+var testStruct = iterationTestStruct{
+	Host:                  "localhost",
+	Port:                  80,
+	iterationTestEmbedded: iterationTestEmbedded{Debug: true, Name: "config"},
+	Secret:                "hidden",
+	internal:              "hidden",
 }
 
 var iterationTestBindings = map[string]any{
-	"array": []string{"first", "second", "third"},
-	// hash has only one element, since iteration order is non-deterministic
-	"map":       map[string]any{"a": 1},
-	"keyed_map": IterationKeyedMap(map[string]any{"a": 1, "b": 2}),
-	"map_slice": yaml.MapSlice{{Key: "a", Value: 1}, {Key: "b", Value: 2}},
+	"array":          []string{"first", "second", "third"},
+	"map":            map[string]any{"a": 1},
+	"string_map":     map[string]string{"a": "w", "10": "x", "9": "y", "B": "z"},
+	"int_map":        map[int]string{-1: "w", 2: "x", 10: "y", 100: "z"},
+	"any_map":        map[any]int{2: 0, 1.5: 0, 1: 0},
+	"keyed_map":      IterationKeyedMap(map[string]any{"a": 1, "b": 2}),
+	"map_slice":      yaml.MapSlice{{Key: "a", Value: 1}, {Key: "b", Value: 2}},
+	"struct":         testStruct,
+	"struct_ptr":     &testStruct,
+	"empty_struct":   struct{}{},
+	"nil_struct_ptr": (*iterationTestStruct)(nil),
 	"products": []string{
 		"Cool Shirt", "Alien Poster", "Batman Poster", "Bullseye Shirt", "Another Classic Vinyl", "Awesome Jeans",
 	},
@@ -179,6 +291,88 @@ func TestIterationTags(t *testing.T) {
 	}
 }
 
+func TestIterationTags_map_order(t *testing.T) {
+	config := render.NewConfig()
+	AddStandardTags(config)
+	m := map[string]int{}
+	for i := range 100 {
+		m[fmt.Sprintf("key%d", i)] = i
+	}
+	bindings := map[string]any{"m": m}
+	root, err := config.Compile(`{% for p in m %}{{ p[0] }}={{ p[1] }},{% endfor %}`, parser.SourceLoc{})
+	require.NoError(t, err)
+	render := func() string {
+		buf := new(bytes.Buffer)
+		require.NoError(t, render.Render(root, buf, bindings, config))
+		return buf.String()
+	}
+	first := render()
+	require.True(t, strings.HasPrefix(first, "key0=0,key1=1,key10=10,"), first)
+	for range 5 {
+		require.Equal(t, first, render())
+	}
+}
+
+func TestIterationTags_cycle_reset(t *testing.T) {
+	config := render.NewConfig()
+	AddStandardTags(config)
+	root, err := config.Compile(`{% for a in array limit:2 %}{% cycle 'g': 'a', 'b', 'c' %}{% endfor %}`, parser.SourceLoc{})
+	require.NoError(t, err)
+	for range 2 {
+		buf := new(bytes.Buffer)
+		require.NoError(t, render.Render(root, buf, iterationTestBindings, config))
+		require.Equal(t, "ab", buf.String())
+	}
+}
+
+func TestIterationTags_strict_loop_variables(t *testing.T) {
+	cfg := render.NewConfig()
+	AddStandardTags(cfg)
+	renderString := func(src string) (string, render.Error) {
+		root, err := cfg.Compile(src, parser.SourceLoc{Pathname: "page.html", LineNo: 1})
+		require.NoErrorf(t, err, src)
+		buf := new(bytes.Buffer)
+		rerr := render.Render(root, buf, iterationTestBindings, cfg)
+		return buf.String(), rerr
+	}
+	inLoops := []string{
+		"{% for a in array %}\n{% assign forloop = 5 %}{% endfor %}",
+		"{% for a in array %}\n{% capture forloop %}x{% endcapture %}{% endfor %}",
+		"{% for a in array %}\n{% assign b, forloop = array %}{% endfor %}",
+		"{% tablerow a in array %}\n{% assign tablerowloop = 5 %}{% endtablerow %}",
+	}
+	// outside a loop, these are ordinary variables
+	outsideLoops := map[string]string{
+		`{% assign forloop = 1 %}{{ forloop }}`:                                 "1",
+		`{% capture tablerowloop %}x{% endcapture %}{{ tablerowloop }}`:         "x",
+		`{% for a in array %}{% endfor %}{% assign forloop = 2 %}{{ forloop }}`: "2",
+	}
+
+	checkOutsideLoops := func() {
+		for src, expected := range outsideLoops {
+			out, err := renderString(src)
+			require.NoErrorf(t, err, src)
+			require.Equalf(t, expected, out, src)
+		}
+	}
+	checkOutsideLoops()
+
+	cfg.StrictVariables = true
+	for _, src := range inLoops {
+		_, err := renderString(src)
+		require.NoErrorf(t, err, "strict variables don't affect this: %s", src)
+	}
+
+	cfg.StrictLoopVariables = true
+	for _, src := range inLoops {
+		_, err := renderString(src)
+		require.Errorf(t, err, src)
+		require.Containsf(t, err.Error(), "is reserved for loop state", src)
+		require.Equalf(t, 2, err.LineNumber(), src)
+	}
+	checkOutsideLoops()
+}
+
 func TestIterationTags_errors(t *testing.T) {
 	cfg := render.NewConfig()
 	AddStandardTags(cfg)
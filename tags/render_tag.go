@@ -0,0 +1,115 @@
+package tags
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/osteele/liquid/expressions"
+	"github.com/osteele/liquid/render"
+)
+
+// These follow the QuotedString and QuotedFragment patterns of Shopify Liquid.
+const (
+	quotedString   = `"[^"]*"|'[^']*'`
+	quotedFragment = quotedString + `|(?:[^\s,|'"]|` + quotedString + `)+`
+)
+
+var (
+	renderTagSyntax = regexp.MustCompile(`^\s*(` + quotedString + `)(?:\s+(with|for)\s+(` + quotedFragment + `))?(?:\s+as\s+([\w-]+))?`)
+	renderTagArg    = regexp.MustCompile(`(\w[\w-]*)\s*:\s*(` + quotedFragment + `)`)
+	renderTagSep    = regexp.MustCompile(`^[\s,]*$`)
+)
+
+type renderArg struct {
+	name string
+	expr expressions.Expression
+}
+
+// renderTag implements {% render 'name' %}, {% render 'name', key: value… %},
+// {% render 'name' with expr [as alias] %} and {% render 'name' for expr [as alias] %}.
+//
+// Unlike include, the partial sees only the variables that are passed to it.
+func renderTag(source string) (func(io.Writer, render.Context) error, error) {
+	m := renderTagSyntax.FindStringSubmatchIndex(source)
+	if m == nil {
+		return nil, fmt.Errorf("syntax error in %q", source)
+	}
+	group := func(i int) string {
+		if m[2*i] < 0 {
+			return ""
+		}
+		return source[m[2*i]:m[2*i+1]]
+	}
+	name := group(1)
+	name = name[1 : len(name)-1]
+	mode := group(2)
+	var subject expressions.Expression
+	if mode != "" {
+		expr, err := expressions.Parse(group(3))
+		if err != nil {
+			return nil, err
+		}
+		subject = expr
+	}
+	alias := group(4)
+	if alias == "" {
+		alias = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	}
+	rest := source[m[1]:]
+	var args []renderArg
+	for _, am := range renderTagArg.FindAllStringSubmatch(rest, -1) {
+		expr, err := expressions.Parse(am[2])
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, renderArg{am[1], expr})
+	}
+	if !renderTagSep.MatchString(renderTagArg.ReplaceAllString(rest, "")) {
+		return nil, fmt.Errorf("syntax error in %q", source)
+	}
+
+	return func(w io.Writer, ctx render.Context) error {
+		filename := filepath.Join(filepath.Dir(ctx.SourceFile()), name)
+		bindings := map[string]any{}
+		for _, arg := range args {
+			value, err := ctx.Evaluate(arg.expr)
+			if err != nil {
+				return err
+			}
+			bindings[arg.name] = value
+		}
+		renderOnce := func(b map[string]any) error {
+			return ctx.RenderFileIsolatedTo(w, filename, b)
+		}
+		if subject == nil {
+			return renderOnce(bindings)
+		}
+		value, err := ctx.Evaluate(subject)
+		if err != nil {
+			return err
+		}
+		var iter iterable
+		if mode == "for" {
+			iter = makeIterator(value)
+		}
+		if iter == nil {
+			bindings[alias] = value
+			return renderOnce(bindings)
+		}
+		for i, l := 0, iter.Len(); i < l; i++ {
+			b := make(map[string]any, len(bindings)+2)
+			for k, v := range bindings {
+				b[k] = v
+			}
+			b[alias] = iter.Index(i)
+			b[forloopVarName] = forloopObject(i, l, nil)
+			if err := renderOnce(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,117 @@
+package tags
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/osteele/liquid/parser"
+	"github.com/osteele/liquid/render"
+	"github.com/stretchr/testify/require"
+)
+
+var renderTagPartials = map[string]string{
+	"testdata/plain":           `plain`,
+	"testdata/args":            `{{ a }}-{{ b }}`,
+	"testdata/scope":           `[{{ x }}]{% assign y = 2 %}`,
+	"testdata/product":         `{{ product.title }}`,
+	"testdata/item":            `{{ item }}{{ forloop.index }}{% if forloop.last %}.{% else %},{% endif %}`,
+	"testdata/sub/card.liquid": `<{{ card }}{{ extra }}>`,
+	"testdata/countdown":       `{{ n }}{% if n > 0 %}{% render 'countdown', n: m %}{% endif %}`,
+	"testdata/self":            `{% render 'self' %}`,
+}
+
+var renderTagTests = []struct{ in, expected string }{
+	{`{% render 'plain' %}`, "plain"},
+	{`{% render "plain" %}`, "plain"},
+	{`{% render 'args', a: 1, b: "two" %}`, "1-two"},
+	{`{% render 'args' a: x b: obj.a %}`, "123-1"},
+	{`{% render 'args', a: 'x,y' %}`, "x,y-"},
+
+	// isolated scope
+	{`{% render 'scope' %}`, "[]"},
+	{`{% render 'scope', x: 'passed' %}`, "[passed]"},
+	{`{% render 'scope' %}{{ y }}`, "[]"},
+
+	// with
+	{`{% render 'product' with page %}`, ""},
+	{`{% render 'product' with products[0] %}`, "Shirt"},
+	{`{% render 'product' with products[1] as product %}`, "Hat"},
+	{`{% render 'args' with 1 as a, b: 2 %}`, "1-2"},
+	{`{% render 'sub/card.liquid' with "c" %}`, "<c>"},
+	{`{% render 'sub/card.liquid' with "c", extra: "!" %}`, "<c!>"},
+
+	// for
+	{`{% render 'item' for animals %}`, "zebra1,octopus2,giraffe3,Sally Snake4."},
+	{`{% render 'product' for products %}`, "ShirtHat"},
+	{`{% render 'args' for animals as a, b: 1 %}`, "zebra-1octopus-1giraffe-1Sally Snake-1"},
+	{`{% render 'item' for "single" %}`, "single,"},
+	{`{% render 'item' for empty %}`, ""},
+
+	// recursion
+	{`{% render 'countdown', n: 1, m: 0 %}`, "10"},
+}
+
+var renderTagErrorTests = []struct{ in, expected string }{
+	{`{% render 'missing' %}`, "no such file"},
+	{`{% render 'self' %}`, "include depth limit of 100 exceeded: testdata/self > testdata/self"},
+}
+
+var renderTagSyntaxErrorTests = []struct{ in, expected string }{
+	{`{% render %}`, "syntax error"},
+	{`{% render partial %}`, "syntax error"},
+	{`{% render 'plain' junk %}`, "syntax error"},
+}
+
+func renderTagTestConfig() render.Config {
+	config := render.NewConfig()
+	AddStandardTags(config)
+	for k, v := range renderTagPartials {
+		config.Cache[k] = []byte(v)
+	}
+	return config
+}
+
+func TestRenderTag(t *testing.T) {
+	config := renderTagTestConfig()
+	loc := parser.SourceLoc{Pathname: "testdata/render_source.html", LineNo: 1}
+	bindings := map[string]any{
+		"x":        123,
+		"obj":      map[string]any{"a": 1},
+		"animals":  []string{"zebra", "octopus", "giraffe", "Sally Snake"},
+		"products": []map[string]any{{"title": "Shirt"}, {"title": "Hat"}},
+		"empty":    []string{},
+	}
+	for i, test := range renderTagTests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			root, err := config.Compile(test.in, loc)
+			require.NoErrorf(t, err, test.in)
+			buf := new(bytes.Buffer)
+			err = render.Render(root, buf, bindings, config)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, buf.String(), test.in)
+		})
+	}
+}
+
+func TestRenderTag_errors(t *testing.T) {
+	config := renderTagTestConfig()
+	loc := parser.SourceLoc{Pathname: "testdata/render_source.html", LineNo: 1}
+	for i, test := range renderTagErrorTests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			root, err := config.Compile(test.in, loc)
+			require.NoErrorf(t, err, test.in)
+			err = render.Render(root, io.Discard, map[string]any{}, config)
+			require.Errorf(t, err, test.in)
+			require.Containsf(t, err.Error(), test.expected, test.in)
+		})
+	}
+	for i, test := range renderTagSyntaxErrorTests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			_, err := config.Compile(test.in, loc)
+			require.Errorf(t, err, test.in)
+			require.Containsf(t, err.Error(), test.expected, test.in)
+		})
+	}
+}
@@ -2,7 +2,11 @@
 package tags
 
 import (
+	"fmt"
 	"io"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/osteele/liquid/expressions"
 	"github.com/osteele/liquid/render"
@@ -11,7 +15,11 @@ import (
 // AddStandardTags defines the standard Liquid tags.
 func AddStandardTags(c render.Config) {
 	c.AddTag("assign", assignTag)
+	c.AddTag("decrement", counterTag(-1))
+	c.AddTag("echo", echoTag)
 	c.AddTag("include", includeTag)
+	c.AddTag("increment", counterTag(1))
+	c.AddTag("render", renderTag)
 
 	// blocks
 	// The parser only recognize the comment and raw tags if they've been defined,
@@ -24,9 +32,10 @@ func AddStandardTags(c render.Config) {
 	c.AddBlock("comment")
 	c.AddBlock("for").Clause("else").Compiler(loopTagCompiler)
 	c.AddBlock("if").Clause("else").Clause("elsif").Compiler(ifTagCompiler(true))
+	c.AddBlock("ifchanged").Compiler(ifchangedTagCompiler)
 	c.AddBlock("raw")
 	c.AddBlock("tablerow").Compiler(loopTagCompiler)
-	c.AddBlock("unless").Clause("else").Compiler(ifTagCompiler(false))
+	c.AddBlock("unless").Clause("else").Clause("elsif").Compiler(ifTagCompiler(false))
 }
 
 func assignTag(source string) (func(io.Writer, render.Context) error, error) {
@@ -39,12 +48,58 @@ func assignTag(source string) (func(io.Writer, render.Context) error, error) {
 		if err != nil {
 			return err
 		}
-		_ = value
-		ctx.Set(stmt.Assignment.Variable, value)
+		names := stmt.Assignment.Variables
+		if len(names) < 2 {
+			return setVariable(ctx, stmt.Assignment.Variable, value)
+		}
+		// {% assign a, b = array %} binds the elements of array to a and b.
+		// Names without a corresponding element are bound to nil.
+		rv := reflect.ValueOf(value)
+		if k := rv.Kind(); k != reflect.Array && k != reflect.Slice {
+			rv = reflect.ValueOf([]any{value})
+		}
+		for i, name := range names {
+			var elem any
+			if i < rv.Len() {
+				elem = rv.Index(i).Interface()
+			}
+			if err := setVariable(ctx, name, elem); err != nil {
+				return err
+			}
+		}
 		return nil
 	}, nil
 }
 
+// setVariable sets a variable for assign and capture. Inside a loop, the
+// variables that hold its state are reserved: an assignment to one of these is
+// an error if the engine has strict loop variables, and is otherwise ignored.
+func setVariable(ctx render.Context, name string, value any) error {
+	if (name == forloopVarName || name == tablerowloopVarName) && ctx.ForLoop() != nil {
+		if ctx.StrictLoopVariables() {
+			return ctx.Errorf("cannot assign to %q; it is reserved for loop state", name)
+		}
+		return nil
+	}
+	ctx.Set(name, value)
+	return nil
+}
+
+// echoTag implements {% echo expr %}, which renders expr as {{ expr }} does.
+func echoTag(source string) (func(io.Writer, render.Context) error, error) {
+	expr, err := expressions.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	return func(w io.Writer, ctx render.Context) error {
+		value, err := ctx.Evaluate(expr)
+		if err != nil {
+			return err
+		}
+		return render.WriteValue(w, value)
+	}, nil
+}
+
 func captureTagCompiler(node render.BlockNode) (func(io.Writer, render.Context) error, error) {
 	// TODO verify syntax
 	varname := node.Args
@@ -53,7 +108,37 @@ func captureTagCompiler(node render.BlockNode) (func(io.Writer, render.Context)
 		if err != nil {
 			return err
 		}
-		ctx.Set(varname, s)
-		return nil
+		return setVariable(ctx, varname, s)
 	}, nil
 }
+
+// countersVarName holds the counters of the increment and decrement tags.
+// These are a separate namespace from assigned variables.
+const countersVarName = ".counters"
+
+// counterTag returns the compiler for increment (delta = 1) and decrement
+// (delta = -1). As in Shopify Liquid, increment renders the value before
+// adding one, and decrement renders the value after subtracting one; both
+// start from 0, and share a counter of the same name.
+func counterTag(delta int) func(string) (func(io.Writer, render.Context) error, error) {
+	return func(source string) (func(io.Writer, render.Context) error, error) {
+		name := strings.TrimSpace(source)
+		if name == "" || strings.ContainsAny(name, " \t\n") {
+			return nil, fmt.Errorf("syntax error in %q", source)
+		}
+		return func(w io.Writer, ctx render.Context) error {
+			counters, _ := ctx.Get(countersVarName).(map[string]int)
+			if counters == nil {
+				counters = map[string]int{}
+				ctx.Set(countersVarName, counters)
+			}
+			n := counters[name]
+			counters[name] = n + delta
+			if delta < 0 {
+				n += delta
+			}
+			_, err := io.WriteString(w, strconv.Itoa(n))
+			return err
+		}, nil
+	}
+}
@@ -14,7 +14,13 @@ import (
 var parseErrorTests = []struct{ in, expected string }{
 	{"{% undefined_tag %}", "undefined tag"},
 	{"{% assign v x y z %}", "syntax error"},
+	{"{% assign a, = 1 %}", "syntax error"},
+	{"{% assign a, b.c = 1 %}", "syntax error"},
 	{"{% if syntax error %}", `unterminated "if" block`},
+	{"{% increment %}", "syntax error"},
+	{"{% decrement a b %}", "syntax error"},
+	{"{% liquid if true\n assign a = 1 %}", `unterminated "if" block`},
+	{"{% liquid undefined_tag %}", "undefined tag"},
 	// TODO once expression parsing is moved to template parse stage
 	// {"{% if syntax error %}{% endif %}", "syntax error"},
 	// {"{% for a in ar undefined %}{{ a }} {% endfor %}", "TODO"},
@@ -25,14 +31,68 @@ var tagTests = []struct{ in, expected string }{
 	{`{% assign av = 1 %}{{ av }}`, "1"},
 	{`{% assign av = obj.a %}{{ av }}`, "1"},
 	{`{% assign av = (1..5) %}{{ av }}`, "{1 5}"},
+	{`{% assign a, b, c = animals %}{{ a }},{{ b }},{{ c }}`, "zebra,octopus,giraffe"},
+	{`{% assign a, b = animals %}{{ a }},{{ b }}`, "zebra,octopus"},
+	{`{% assign a, b, c, d, e = animals %}{{ a }},{{ d }},{{ e }}.`, "zebra,Sally Snake,."},
+	{`{% assign e = 1 %}{% assign a,b,e = obj.a %}{{ a }},{{ b }},{{ e }}.`, "1,,."},
 	{`{% capture x %}captured{% endcapture %}{{ x }}`, "captured"},
 
+	// echo tag
+	{`{% echo obj.a %}`, "1"},
+	{`{% echo "text" %}`, "text"},
+	{`{% echo animals %}`, "zebraoctopusgiraffeSally Snake"},
+	{`{% echo undefined_variable %}`, ""},
+	{`a {%- echo x -%} b`, "a123b"},
+
+	// counter tags
+	{`{% increment n %}{% increment n %}{% increment n %}`, "012"},
+	{`{% decrement n %}{% decrement n %}{% decrement n %}`, "-1-2-3"},
+	{`{% increment n %}{% increment n %}{% decrement n %}{% decrement n %}{% increment n %}`, "01100"},
+	{`{% increment a %}{% increment b %}{% increment a %}{% decrement b %}`, "0010"},
+	{`{% assign n = 10 %}{% increment n %}{% increment n %}{{ n }}{% decrement n %}{{ n }}`, "0110110"},
+	{`{% increment x %}{{ x }}{% assign x = 5 %}{% increment x %}{{ x }}`, "012315"},
+	{`{% for i in (1..3) %}{% increment n %}{% endfor %}`, "012"},
+
 	// TODO research whether Liquid requires matching interior tags
 	{`{% comment %}{{ a }}{% undefined_tag %}{% endcomment %}`, ""},
 
 	// TODO research whether Liquid requires matching interior tags
 	{`pre{% raw %}{{ a }}{% undefined_tag %}{% endraw %}post`, "pre{{ a }}{% undefined_tag %}post"},
 	{`pre{% raw %}{% if false %}anyway-{% endraw %}post`, "pre{% if false %}anyway-post"},
+	{`{% raw %}{{ var }} {% if %}{% endraw %}`, "{{ var }} {% if %}"},
+	{`{% raw %}{% if x {{ y {% endraw %}{{ 1 }}`, "{% if x {{ y 1"},
+	{`{% raw %}}} %} {% endif %}{% endraw %}`, "}} %} {% endif %}"},
+	{`{% raw %}{% raw %}{%- endraw -%} post`, "{% raw %}post"},
+
+	// liquid tag
+	{`{% liquid
+		assign n = 2
+		if n > 1
+			increment c
+		else
+			decrement c
+		endif
+	%}{{ n }}`, "02"},
+	{`{% liquid for a in animals
+		increment i
+	endfor %}`, "0123"},
+	{`{% liquid
+		# a comment
+		comment
+			increment i
+		endcomment
+
+		increment i
+	%}`, "0"},
+	{`{% liquid %}`, ""},
+	{`{% liquid
+		assign n = obj.a
+		if n == 1
+			echo "one"
+		endif
+		echo n
+	%}`, "one1"},
+	{"a {%- liquid\n\tassign\tx = 1\n -%} b{{ x }}", "ab1"},
 }
 
 var tagErrorTests = []struct{ in, expected string }{
@@ -46,6 +106,7 @@ var tagTestBindings = map[string]any{
 		"a": 1,
 	},
 	"animals": []string{"zebra", "octopus", "giraffe", "Sally Snake"},
+	"repeats": []int{1, 1, 2, 3, 3, 1},
 	"pages": []map[string]any{
 		{"category": "business"},
 		{"category": "celebrities"},
@@ -2,6 +2,7 @@ package liquid
 
 import (
 	"bytes"
+	"context"
 	"io"
 
 	"github.com/osteele/liquid/parser"
@@ -11,6 +12,10 @@ import (
 // A Template is a compiled Liquid template. It knows how to evaluate itself within a variable binding environment, to create a rendered byte slice.
 //
 // Use Engine.ParseTemplate to create a template.
+//
+// A Template is safe for concurrent use by multiple goroutines. Each render
+// keeps its state, such as variables that the template assigns and the
+// cursors of cycle and increment tags, in a context of its own.
 type Template struct {
 	root render.Node
 	cfg  *render.Config
@@ -41,8 +46,31 @@ func (t *Template) Render(vars Bindings) ([]byte, SourceError) {
 	return buf.Bytes(), nil
 }
 
-// FRender executes the template with the specified variable bindings and renders it into w.
-func (t *Template) FRender(w io.Writer, vars Bindings) SourceError {
+// RenderContext is like Render, but stops if ctx is done before rendering completes.
+// In that case, it returns the output rendered so far, and an error whose Cause is ctx.Err().
+func (t *Template) RenderContext(ctx context.Context, vars Bindings) ([]byte, SourceError) {
+	buf := new(bytes.Buffer)
+	err := render.RenderContext(ctx, t.root, buf, vars, *t.cfg)
+	return buf.Bytes(), err
+}
+
+// RenderWithWarnings is like RenderString, but it also returns warnings for references to undefined
+// variables and properties, and for undefined filters, which are then evaluated as their input
+// instead of stopping the render. Each warning is reported once per source location.
+func (t *Template) RenderWithWarnings(vars Bindings) (string, []Warning, SourceError) {
+	buf := new(bytes.Buffer)
+	warnings, err := render.RenderWarnings(context.Background(), t.root, buf, vars, *t.cfg)
+	if err != nil {
+		return "", warnings, err
+	}
+	return buf.String(), warnings, nil
+}
+
+// RenderTo executes the template with the specified variable bindings, and writes the
+// output to w as it is rendered, instead of collecting it in memory. It stops at the
+// first error from w, and returns it with the location of the template source that
+// was being written.
+func (t *Template) RenderTo(w io.Writer, vars Bindings) SourceError {
 	err := render.Render(t.root, w, vars, *t.cfg)
 	if err != nil {
 		return err
@@ -50,6 +78,12 @@ func (t *Template) FRender(w io.Writer, vars Bindings) SourceError {
 	return nil
 }
 
+// FRender executes the template with the specified variable bindings and renders it into w.
+// It is equivalent to RenderTo.
+func (t *Template) FRender(w io.Writer, vars Bindings) SourceError {
+	return t.RenderTo(w, vars)
+}
+
 // RenderString is a convenience wrapper for Render, that has string input and output.
 func (t *Template) RenderString(b Bindings) (string, SourceError) {
 	bs, err := t.Render(b)
@@ -0,0 +1,71 @@
+package liquid
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+)
+
+// DefaultParseCacheSize is the number of templates that Engine.ParseCached keeps, by default.
+const DefaultParseCacheSize = 1000
+
+// templateCache is a least-recently-used cache of parsed templates, keyed by
+// the hash of their source. It's safe for concurrent use.
+type templateCache struct {
+	sync.Mutex
+	size    int
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List // of *templateCacheEntry, most recently used first
+}
+
+type templateCacheEntry struct {
+	key [sha256.Size]byte
+	tpl *Template
+}
+
+func newTemplateCache(size int) *templateCache {
+	return &templateCache{
+		size:    size,
+		entries: map[[sha256.Size]byte]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+func (c *templateCache) get(key [sha256.Size]byte) (*Template, bool) {
+	c.Lock()
+	defer c.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*templateCacheEntry).tpl, true
+}
+
+func (c *templateCache) add(key [sha256.Size]byte, tpl *Template) {
+	c.Lock()
+	defer c.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&templateCacheEntry{key, tpl})
+	c.evict()
+}
+
+func (c *templateCache) setSize(size int) {
+	c.Lock()
+	defer c.Unlock()
+	c.size = size
+	c.evict()
+}
+
+// evict removes the least recently used entries in excess of the size.
+// The caller must hold the lock.
+func (c *templateCache) evict() {
+	for c.order.Len() > c.size && c.order.Len() > 0 {
+		el := c.order.Back()
+		c.order.Remove(el)
+		delete(c.entries, el.Value.(*templateCacheEntry).key)
+	}
+}
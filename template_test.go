@@ -1,7 +1,11 @@
 package liquid
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 
@@ -103,6 +107,73 @@ func TestTemplate_Render_race(t *testing.T) {
 	wg2.Wait()
 }
 
+func TestTemplate_Render_concurrent(t *testing.T) {
+	engine := NewEngine()
+	engine.RegisterPartialResolver(func(name string) ([]byte, error) {
+		return []byte(`[{{ item }}{% increment n %}]`), nil
+	})
+	tpl, err := engine.ParseString(`{% assign total = 0 %}` +
+		`{% for item in items limit: 2 %}{% cycle "a", "b" %}{% ifchanged %}{{ item }}{% endifchanged %}{% endfor %}` +
+		`{% for item in items offset: continue %}{% render "p", item: item %}{% include "p" %}{% assign total = total | plus: item %}{% endfor %}` +
+		`{% capture c %}{{ name }}{% endcapture %}{{ c }}:{{ total }}{% increment n %}{% decrement m %}`)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bindings := Bindings{"name": fmt.Sprintf("r%d", i), "items": []int{i, i, i + 1, i + 2}}
+			expected := fmt.Sprintf("a%db[%d0][%d0][%d0][%d0]r%d:%d0-1", i, i+1, i+1, i+2, i+2, i, 2*i+3)
+			for range 10 {
+				out, err := tpl.RenderString(bindings)
+				assert.NoError(t, err)
+				assert.Equal(t, expected, out)
+			}
+			assert.NotContains(t, bindings, "total")
+		}(i)
+	}
+	wg.Wait()
+}
+
+// limitedWriter fails once it has been given more than n bytes.
+type limitedWriter struct {
+	buf bytes.Buffer
+	n   int
+}
+
+var errWriterFull = errors.New("writer is full")
+
+func (w *limitedWriter) Write(b []byte) (int, error) {
+	if w.buf.Len()+len(b) > w.n {
+		return 0, errWriterFull
+	}
+	return w.buf.Write(b)
+}
+
+func TestTemplate_RenderTo(t *testing.T) {
+	engine := NewEngine()
+	engine.RegisterPartialResolver(func(name string) ([]byte, error) {
+		return []byte(`{% for i in (1..3) %}{{ i }}{% endfor %};`), nil
+	})
+	tpl, err := engine.ParseString(`{% for n in (1..100) %}{% include "p" %}{% endfor %}`)
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, tpl.RenderTo(buf, emptyBindings))
+	require.Equal(t, strings.Repeat("123;", 100), buf.String())
+
+	w := &limitedWriter{n: 10}
+	err = tpl.RenderTo(w, emptyBindings)
+	require.ErrorIs(t, err, errWriterFull)
+	require.Equal(t, "123;123;12", w.buf.String())
+
+	tpl, err = engine.ParseString(`{{ "abc" }}`)
+	require.NoError(t, err)
+	err = tpl.RenderTo(&limitedWriter{n: 2}, emptyBindings)
+	require.ErrorIs(t, err, errWriterFull)
+}
+
 func BenchmarkTemplate_Render(b *testing.B) {
 	engine := NewEngine()
 	bindings := Bindings{"a": "string value"}
@@ -116,3 +187,58 @@ func BenchmarkTemplate_Render(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestTemplate_RenderContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine := NewEngine()
+	engine.RegisterTag("cancel", func(render.Context) (string, error) {
+		cancel()
+		return "", nil
+	})
+	tpl, err := engine.ParseString(`{% for i in (1..100) %}{{ i }},{% if i == 3 %}{% cancel %}{% endif %}{% endfor %}`)
+	require.NoError(t, err)
+
+	out, err := tpl.RenderContext(ctx, emptyBindings)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Equal(t, context.Canceled, err.Cause())
+	require.Equal(t, "1,2,3,", string(out))
+
+	// with a context that is never done, the template renders completely
+	out, err = engine.ParseAndRenderContext(context.Background(), []byte(`{% for i in (1..3) %}{{ i }}{% endfor %}`), emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "123", string(out))
+
+	ctx, cancelTimeout := context.WithTimeout(context.Background(), 0)
+	defer cancelTimeout()
+	_, err = engine.ParseAndRenderContext(ctx, []byte(`{% for i in (1..3) %}{{ i }}{% endfor %}`), emptyBindings)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestTemplate_RenderWithWarnings(t *testing.T) {
+	engine := NewEngine()
+	src := "Hello {{ name }}!\n{% for i in (1..3) %}{{ greeting }}{% endfor %}{{ page.subtitle }}{{ page.title | shout }}"
+	tpl, err := engine.ParseTemplateLocation([]byte(src), "hello.html", 1)
+	require.NoError(t, err)
+	out, warnings, err := tpl.RenderWithWarnings(map[string]any{"page": map[string]any{"title": "Home"}})
+	require.NoError(t, err)
+	require.Equal(t, "Hello !\nHome", out)
+	require.Equal(t, []Warning{
+		{Message: `undefined variable "name"`, Path: "hello.html", Line: 1, Column: 7},
+		{Message: `undefined variable "greeting"`, Path: "hello.html", Line: 2, Column: 22},
+		{Message: `undefined variable "page.subtitle"`, Path: "hello.html", Line: 2, Column: 48},
+		{Message: `undefined filter "shout"`, Path: "hello.html", Line: 2, Column: 67},
+	}, warnings)
+	assert.Equal(t, `hello.html:1:7: undefined variable "name"`, warnings[0].String())
+
+	out, warnings, err = tpl.RenderWithWarnings(map[string]any{"name": nil, "greeting": "hi", "page": map[string]any{"subtitle": "", "title": ""}})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello !\nhihihi", out)
+	assert.Equal(t, []Warning{{Message: `undefined filter "shout"`, Path: "hello.html", Line: 2, Column: 67}}, warnings)
+
+	_, err = tpl.Render(emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `undefined filter "shout"`)
+}
@@ -21,6 +21,23 @@ func Call(fn reflect.Value, args []any) (any, error) {
 	return convertCallResults(results)
 }
 
+// CallWithKeywordArgs is like Call, for a function whose last parameter is a
+// map type with string keys, such as expressions.KeywordArgs. This parameter
+// receives kwargs; args are converted to the types of the preceding parameters.
+func CallWithKeywordArgs(fn reflect.Value, args []any, kwargs map[string]any) (any, error) {
+	n := fn.Type().NumIn() - 1
+	if len(args) > n {
+		return nil, &CallParityError{NumArgs: len(args), NumParams: n}
+	}
+	in, err := convertCallArguments(fn, args)
+	if err != nil {
+		return nil, err
+	}
+	in[n] = reflect.ValueOf(kwargs).Convert(fn.Type().In(n))
+	results := fn.Call(in)
+	return convertCallResults(results)
+}
+
 // A CallParityError is a mismatch between the argument and parameter counts.
 type CallParityError struct{ NumArgs, NumParams int }
 
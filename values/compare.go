@@ -1,13 +1,11 @@
 package values
 
 import (
+	"cmp"
 	"reflect"
 )
 
-var (
-	int64Type   = reflect.TypeOf(int64(0))
-	float64Type = reflect.TypeOf(float64(0))
-)
+var float64Type = reflect.TypeOf(float64(0))
 
 // Equal returns a bool indicating whether a == b after conversion.
 func Equal(a, b any) bool { //nolint: gocyclo
@@ -15,6 +13,9 @@ func Equal(a, b any) bool { //nolint: gocyclo
 	if a == nil || b == nil {
 		return a == b
 	}
+	if ta, tb, ok := timeOperands(a, b); ok {
+		return ta.Equal(tb)
+	}
 	ra, rb := reflect.ValueOf(a), reflect.ValueOf(b)
 	switch joinKind(ra.Kind(), rb.Kind()) {
 	case reflect.Array, reflect.Slice:
@@ -29,8 +30,9 @@ func Equal(a, b any) bool { //nolint: gocyclo
 		return true
 	case reflect.Bool:
 		return ra.Bool() == rb.Bool()
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return ra.Convert(int64Type).Int() == rb.Convert(int64Type).Int()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareInts(ra, rb) == 0
 	case reflect.Float32, reflect.Float64:
 		return ra.Convert(float64Type).Float() == rb.Convert(float64Type).Float()
 	case reflect.String:
@@ -51,12 +53,16 @@ func Less(a, b any) bool {
 	if a == nil || b == nil {
 		return false
 	}
+	if ta, tb, ok := timeOperands(a, b); ok {
+		return ta.Before(tb)
+	}
 	ra, rb := reflect.ValueOf(a), reflect.ValueOf(b)
 	switch joinKind(ra.Kind(), rb.Kind()) {
 	case reflect.Bool:
 		return !ra.Bool() && rb.Bool()
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return ra.Convert(int64Type).Int() < rb.Convert(int64Type).Int()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareInts(ra, rb) < 0
 	case reflect.Float32, reflect.Float64:
 		return ra.Convert(float64Type).Float() < rb.Convert(float64Type).Float()
 	case reflect.String:
@@ -75,21 +81,41 @@ func joinKind(a, b reflect.Kind) reflect.Kind { //nolint: gocyclo
 		if b == reflect.Array || b == reflect.Slice {
 			return reflect.Slice
 		}
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		if isIntKind(b) {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if isIntKind(b) || isUintKind(b) {
 			return reflect.Int64
 		}
 		if isFloatKind(b) {
 			return reflect.Float64
 		}
 	case reflect.Float32, reflect.Float64:
-		if isIntKind(b) || isFloatKind(b) {
+		if isIntKind(b) || isUintKind(b) || isFloatKind(b) {
 			return reflect.Float64
 		}
 	}
 	return reflect.Invalid
 }
 
+// compareInts compares two integer values without converting them to float64,
+// so that it is exact for magnitudes beyond 2^53. It returns -1, 0, or +1.
+func compareInts(ra, rb reflect.Value) int {
+	au, bu := isUintKind(ra.Kind()), isUintKind(rb.Kind())
+	switch {
+	case au && bu:
+		return cmp.Compare(ra.Uint(), rb.Uint())
+	case au:
+		if rb.Int() < 0 {
+			return 1
+		}
+		return cmp.Compare(ra.Uint(), uint64(rb.Int()))
+	case bu:
+		return -compareInts(rb, ra)
+	default:
+		return cmp.Compare(ra.Int(), rb.Int())
+	}
+}
+
 func isIntKind(k reflect.Kind) bool {
 	switch k {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -99,6 +125,15 @@ func isIntKind(k reflect.Kind) bool {
 	}
 }
 
+func isUintKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
 func isFloatKind(k reflect.Kind) bool {
 	switch k {
 	case reflect.Float32, reflect.Float64:
@@ -30,7 +30,10 @@ var eqTests = []struct {
 	{"a", "b", false},
 	{"a", "a", true},
 	{int8(2), int16(2), true}, // TODO
-	// {uint8(2), int8(2), true}, // FIXME
+	{uint8(2), int8(2), true},
+	{uint64(1) << 63, int64(-1) << 63, false},
+	{int64(9007199254740993), int64(9007199254740992), false},
+	{9007199254740993, uint64(9007199254740993), true},
 	{eqArrayTestObj, eqArrayTestObj[:], true},
 	{[]string{"a"}, []string{"a"}, true},
 	{[]string{"a"}, []string{"a", "b"}, false},
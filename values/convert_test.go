@@ -86,6 +86,7 @@ var convertTests = []struct {
 	{yaml.MapSlice{{Key: nil, Value: 1}}, map[any]string{nil: "1"}},
 	{Range{1, 5}, []any{1, 2, 3, 4, 5}},
 	{Range{0, 0}, []any{0}},
+	{Range{1, 0}, []any{}},
 	// {"March 14, 2016", time.Now(), timeMustParse("2016-03-14T00:00:00Z")},
 	{redConvertible{}, "red"},
 }
@@ -0,0 +1,46 @@
+package values
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// A ValueConverter creates a Value from a Go value of a registered type.
+type ValueConverter func(any) Value
+
+var (
+	convertersMu sync.Mutex
+	// converters is replaced, not modified, so that ValueOf can read it without locking.
+	converters atomic.Pointer[map[reflect.Type]ValueConverter]
+)
+
+// RegisterValueConverter causes ValueOf to use fn to wrap values of type typ,
+// instead of the default reflection-based wrappers.
+//
+// It is safe to call RegisterValueConverter concurrently with itself and with ValueOf.
+func RegisterValueConverter(typ reflect.Type, fn ValueConverter) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	m := map[reflect.Type]ValueConverter{}
+	if old := converters.Load(); old != nil {
+		for k, v := range *old {
+			m[k] = v
+		}
+	}
+	if fn == nil {
+		delete(m, typ)
+	} else {
+		m[typ] = fn
+	}
+	converters.Store(&m)
+}
+
+func findValueConverter(value any) (ValueConverter, bool) {
+	m := converters.Load()
+	if m == nil {
+		return nil, false
+	}
+	fn, ok := (*m)[reflect.TypeOf(value)]
+	return fn, ok
+}
@@ -0,0 +1,63 @@
+package values
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCents int
+
+// testCentsValue adds a "dollars" property to the default integer wrapper.
+type testCentsValue struct{ wrapperValue }
+
+func (v testCentsValue) PropertyValue(iv Value) Value {
+	if iv.Interface() == "dollars" {
+		return ValueOf(float64(v.value.(testCents)) / 100)
+	}
+	return nilValue
+}
+
+func TestRegisterValueConverter(t *testing.T) {
+	typ := reflect.TypeOf(testCents(0))
+	defer RegisterValueConverter(typ, nil)
+
+	require.Nil(t, ValueOf(testCents(150)).PropertyValue(ValueOf("dollars")).Interface())
+
+	RegisterValueConverter(typ, func(v any) Value { return testCentsValue{wrapperValue{v}} })
+	cv := ValueOf(testCents(150))
+	require.IsType(t, testCentsValue{}, cv)
+	require.Equal(t, 1.5, cv.PropertyValue(ValueOf("dollars")).Interface())
+	require.Equal(t, testCents(150), cv.Interface())
+
+	// other types are unaffected
+	require.IsType(t, wrapperValue{}, ValueOf(150))
+
+	RegisterValueConverter(typ, nil)
+	require.Nil(t, ValueOf(testCents(150)).PropertyValue(ValueOf("dollars")).Interface())
+}
+
+func TestRegisterValueConverter_race(t *testing.T) {
+	type testKey string
+	typ := reflect.TypeOf(testKey(""))
+	defer RegisterValueConverter(typ, nil)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterValueConverter(typ, func(v any) Value {
+				return ValueOf(strings.ToUpper(string(v.(testKey))))
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = ValueOf(testKey("k"))
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, "K", ValueOf(testKey("k")).Interface())
+}
@@ -34,6 +34,43 @@ func (w *dropWrapper) Less(o Value) bool           { return w.Resolve().Less(o)
 func (w *dropWrapper) IndexValue(i Value) Value    { return w.Resolve().IndexValue(i) }
 func (w *dropWrapper) Contains(o Value) bool       { return w.Resolve().Contains(o) }
 func (w *dropWrapper) Int() int                    { return w.Resolve().Int() }
+func (w *dropWrapper) Int64() (int64, bool)        { return w.Resolve().Int64() }
+func (w *dropWrapper) Float64() (float64, bool)    { return w.Resolve().Float64() }
 func (w *dropWrapper) Interface() any              { return w.Resolve().Interface() }
 func (w *dropWrapper) PropertyValue(k Value) Value { return w.Resolve().PropertyValue(k) }
 func (w *dropWrapper) Test() bool                  { return w.Resolve().Test() }
+
+// A PropertyDrop controls which properties it exposes to templates.
+// Liquid(key) returns the value of the named property, and whether
+// the drop has that property.
+type PropertyDrop interface {
+	Liquid(key string) (any, bool)
+}
+
+type propertyDropValue struct{ wrapperValue }
+
+func (v propertyDropValue) drop() PropertyDrop { return v.value.(PropertyDrop) }
+
+func (v propertyDropValue) Contains(elem Value) bool {
+	name, ok := elem.Interface().(string)
+	if !ok {
+		return false
+	}
+	_, found := v.drop().Liquid(name)
+	return found
+}
+
+func (v propertyDropValue) IndexValue(index Value) Value {
+	return v.PropertyValue(index)
+}
+
+func (v propertyDropValue) PropertyValue(index Value) Value {
+	name, ok := index.Interface().(string)
+	if !ok {
+		return nilValue
+	}
+	if value, found := v.drop().Liquid(name); found {
+		return ValueOf(value)
+	}
+	return nilValue
+}
@@ -61,3 +61,35 @@ func BenchmarkDrop_Resolve_3(b *testing.B) {
 		}
 	}
 }
+
+type testPropertyDrop struct{ secret string }
+
+func (d testPropertyDrop) Liquid(key string) (any, bool) {
+	switch key {
+	case "name":
+		return "drop", true
+	case "shout":
+		return d.secret + "!", true
+	case "missing":
+		return nil, true
+	}
+	return nil, false
+}
+
+func TestValue_propertyDrop(t *testing.T) {
+	dv := ValueOf(testPropertyDrop{"hi"})
+	require.Equal(t, "drop", dv.PropertyValue(ValueOf("name")).Interface())
+	require.Equal(t, "hi!", dv.PropertyValue(ValueOf("shout")).Interface())
+	require.Equal(t, "drop", dv.IndexValue(ValueOf("name")).Interface())
+	require.Nil(t, dv.PropertyValue(ValueOf("secret")).Interface())
+	require.Nil(t, dv.PropertyValue(ValueOf(1)).Interface())
+
+	require.True(t, dv.Contains(ValueOf("name")))
+	require.True(t, dv.Contains(ValueOf("missing")))
+	require.False(t, dv.Contains(ValueOf("secret")))
+	require.False(t, dv.Contains(ValueOf(1)))
+
+	// pointers to drops are drops too
+	pv := ValueOf(&testPropertyDrop{"ho"})
+	require.Equal(t, "ho!", pv.PropertyValue(ValueOf("shout")).Interface())
+}
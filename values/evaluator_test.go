@@ -60,4 +60,15 @@ func TestSort(t *testing.T) {
 	require.Nil(t, array[0].(map[string]any)["key"])
 	require.Equal(t, 10, array[1].(map[string]any)["key"])
 	require.Equal(t, 20, array[2].(map[string]any)["key"])
+
+	type item struct{ Key any }
+	array = []any{item{20}, item{nil}, item{"x"}, item{10}, map[string]any{"Key": 10}}
+	SortByProperty(array, "Key", false)
+	require.Equal(t, []any{item{10}, map[string]any{"Key": 10}, item{20}, item{"x"}, item{nil}}, array)
+
+	// a mix of types compares as strings, whatever the input order
+	for _, array := range [][]any{{10, "1a", 2, "0", 3, "b", nil}, {"1a", nil, 2, 3, 10, "0", "b"}} {
+		Sort(array)
+		require.Equal(t, []any{"0", 10, "1a", 2, 3, "b", nil}, array)
+	}
 }
@@ -0,0 +1,60 @@
+package values
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// IndexValueFold is like v.IndexValue(index), except that if v is a map with
+// string keys that doesn't contain index, it returns the value of a key that
+// matches index case-insensitively. If several keys match, it uses the first in
+// sorted order.
+func IndexValueFold(v, index Value) Value {
+	if mv, ok := resolveMapValue(v); ok {
+		if value, found := mv.indexValueFold(index); found {
+			return value
+		}
+	}
+	return v.IndexValue(index)
+}
+
+// PropertyValueFold is like v.PropertyValue(index), but matches map keys as IndexValueFold does.
+func PropertyValueFold(v, index Value) Value {
+	if mv, ok := resolveMapValue(v); ok {
+		if value, found := mv.indexValueFold(index); found {
+			return value
+		}
+	}
+	return v.PropertyValue(index)
+}
+
+func resolveMapValue(v Value) (mapValue, bool) {
+	if dw, ok := v.(*dropWrapper); ok {
+		v = dw.Resolve()
+	}
+	mv, ok := v.(mapValue)
+	return mv, ok
+}
+
+// indexValueFold looks up the exact key, and then (only if that misses) scans
+// for a case-insensitive match.
+func (mv mapValue) indexValueFold(iv Value) (Value, bool) {
+	mr := reflect.ValueOf(mv.value)
+	name, ok := iv.Interface().(string)
+	if !ok || mr.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	kt := mr.Type().Key()
+	if er := mr.MapIndex(reflect.ValueOf(name).Convert(kt)); er.IsValid() {
+		return ValueOf(er.Interface()), true
+	}
+	keys := mr.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, k := range keys {
+		if strings.EqualFold(k.String(), name) {
+			return ValueOf(mr.MapIndex(k).Interface()), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,35 @@
+package values
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexValueFold(t *testing.T) {
+	hv := ValueOf(map[string]any{"Name": "n", "KEY": "upper", "Key": "title", "key": "lower"})
+	require.Equal(t, "n", IndexValueFold(hv, ValueOf("name")).Interface())
+	require.Equal(t, "n", PropertyValueFold(hv, ValueOf("NAME")).Interface())
+
+	// exact matches win
+	require.Equal(t, "lower", IndexValueFold(hv, ValueOf("key")).Interface())
+	require.Equal(t, "title", PropertyValueFold(hv, ValueOf("Key")).Interface())
+
+	// otherwise the first key in sorted order
+	require.Equal(t, "upper", IndexValueFold(hv, ValueOf("kEY")).Interface())
+
+	// misses
+	require.Nil(t, IndexValueFold(hv, ValueOf("missing")).Interface())
+	require.Nil(t, IndexValueFold(hv, ValueOf(1)).Interface())
+	require.Equal(t, 4, PropertyValueFold(hv, ValueOf("size")).Interface())
+
+	// non-maps fall through to the usual lookup
+	av := ValueOf([]string{"first"})
+	require.Equal(t, "first", IndexValueFold(av, ValueOf(0)).Interface())
+	require.Equal(t, "first", PropertyValueFold(av, ValueOf("first")).Interface())
+
+	// named string key types
+	type keyType string
+	kv := ValueOf(map[keyType]int{"Count": 1})
+	require.Equal(t, 1, PropertyValueFold(kv, ValueOf("count")).Interface())
+}
@@ -0,0 +1,64 @@
+package values
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format returns the text of a value as an object {{ value }} renders it. nil is
+// empty, a time has the format 2006-01-02 15:04:05 -0700, floats don't have Go's
+// exponent notation, and the elements of an array or slice are concatenated.
+func Format(value any) string {
+	var b strings.Builder
+	writeFormat(&b, value)
+	return b.String()
+}
+
+func writeFormat(b *strings.Builder, value any) {
+	value = ToLiquid(value)
+	if value == nil {
+		return
+	}
+	switch value := value.(type) {
+	case time.Time:
+		b.WriteString(value.Format("2006-01-02 15:04:05 -0700"))
+		return
+	case []byte:
+		b.Write(value)
+		return
+		// there used be a case on fmt.Stringer here, but fmt.Sprint produces better results than obj.Write
+		// for instances of error and *string
+	case float64:
+		strVal := strconv.FormatFloat(value, 'f', -1, 64)
+
+		// very debatable whether we should add the .0.
+		// If we don't, {{ 123.00 }} will render as "123"
+		// but in official liquid it's "123.0"
+		// not doing it for now. Might change it later.
+		// if !strings.Contains(strVal, ".") {
+		// 	strVal = strVal + ".0"
+		// }
+		b.WriteString(strVal)
+		return
+	case float32:
+		b.WriteString(strconv.FormatFloat(float64(value), 'f', -1, 64))
+		return
+	}
+	rt := reflect.ValueOf(value)
+	switch rt.Kind() {
+	case reflect.Array, reflect.Slice:
+		for i := range rt.Len() {
+			item := rt.Index(i)
+			if item.IsValid() {
+				writeFormat(b, item.Interface())
+			}
+		}
+	case reflect.Ptr:
+		writeFormat(b, reflect.ValueOf(value).Elem())
+	default:
+		b.WriteString(fmt.Sprint(value))
+	}
+}
@@ -0,0 +1,30 @@
+package values
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	s := "text"
+	tm := time.Date(2024, 3, 5, 14, 7, 0, 0, time.UTC)
+	for _, test := range []struct {
+		value    any
+		expected string
+	}{
+		{nil, ""},
+		{"text", "text"},
+		{[]byte("bytes"), "bytes"},
+		{12, "12"},
+		{1e20, "100000000000000000000"},
+		{float32(2.5), "2.5"},
+		{true, "true"},
+		{tm, "2024-03-05 14:07:00 +0000"},
+		{[]any{"a", 1, nil, []int{2, 3}}, "a123"},
+		{&s, "text"},
+	} {
+		require.Equalf(t, test.expected, Format(test.value), "%#v", test.value)
+	}
+}
@@ -46,10 +46,17 @@ var dateLayouts = []string{
 	"Jan 2 2006",
 }
 
-// ParseDate tries a few heuristics to parse a date from a string
+// ParseDate tries a few heuristics to parse a date from a string. As in
+// Shopify, "now" and "today" are the current time.
 func ParseDate(s string) (time.Time, error) {
-	if s == "now" {
-		return time.Now(), nil
+	return ParseDateInLocation(s, time.Local)
+}
+
+// ParseDateInLocation is like ParseDate, but it tries layouts before its own,
+// and it reads dates that lack a time zone in loc.
+func ParseDateInLocation(s string, loc *time.Location, layouts ...string) (time.Time, error) {
+	if s == "now" || s == "today" {
+		return time.Now().In(loc), nil
 	}
 	// Parse potentially Unix timestamps.
 	if isOnlyNumbers(s) {
@@ -57,12 +64,14 @@ func ParseDate(s string) (time.Time, error) {
 		if err != nil {
 			return time.Time{}, err
 		}
-		return time.Unix(i, 0), nil
+		return time.Unix(i, 0).In(loc), nil
 	}
-	for _, layout := range dateLayouts {
-		t, err := time.ParseInLocation(layout, s, time.Local)
-		if err == nil {
-			return t, nil
+	for _, ls := range [][]string{layouts, dateLayouts} {
+		for _, layout := range ls {
+			t, err := time.ParseInLocation(layout, s, loc)
+			if err == nil {
+				return t, nil
+			}
 		}
 	}
 	return zeroTime, conversionError("", s, reflect.TypeOf(zeroTime))
@@ -12,6 +12,10 @@ func TestConstant(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, dt.After(timeMustParse("1970-01-01T00:00:00Z")))
 
+	dt, err = ParseDate("today")
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), dt, time.Minute)
+
 	dt, err = ParseDate("2017-07-09 10:40:00 UTC")
 	require.NoError(t, err)
 	require.Equal(t, timeMustParse("2017-07-09T10:40:00Z"), dt)
@@ -20,3 +24,20 @@ func TestConstant(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, timeMustParse("2024-10-27T14:48:44Z"), dt.In(time.UTC))
 }
+
+func TestParseDateInLocation(t *testing.T) {
+	loc := time.FixedZone("X", 2*60*60)
+	dt, err := ParseDateInLocation("2017-07-09 10:40:00", loc)
+	require.NoError(t, err)
+	require.Equal(t, timeMustParse("2017-07-09T08:40:00Z"), dt.In(time.UTC))
+
+	dt, err = ParseDateInLocation("2017-07-09T10:40:00Z", loc)
+	require.NoError(t, err)
+	require.Equal(t, timeMustParse("2017-07-09T10:40:00Z"), dt)
+
+	_, err = ParseDateInLocation("09.07.2017", loc)
+	require.Error(t, err)
+	dt, err = ParseDateInLocation("09.07.2017", loc, "02.01.2006")
+	require.NoError(t, err)
+	require.Equal(t, timeMustParse("2017-07-08T22:00:00Z"), dt.In(time.UTC))
+}
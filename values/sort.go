@@ -1,41 +1,94 @@
 package values
 
 import (
+	"fmt"
 	"reflect"
 	"sort"
+	"time"
 )
 
-// Sort any []any value.
+// Sort any []any value. The sort is stable, and nil elements sort last.
+// Elements of different types, such as a number and a string, compare as strings.
 func Sort(data []any) {
-	sort.Sort(genericSortable(data))
+	keys := make([]any, len(data))
+	copy(keys, data)
+	SortByKeys(data, keys, false, false)
 }
 
-type genericSortable []any
-
-// Len is part of sort.Interface.
-func (s genericSortable) Len() int {
-	return len(s)
+// SortByProperty sorts a slice by the value of a property of its elements, which
+// can be maps, structs, or drops. The sort is stable. Elements that lack the property,
+// or where it is nil, sort first if nilFirst is true, and otherwise last. If the
+// property values have different types, such as a number and a string, they all
+// compare as strings.
+func SortByProperty(data []any, key string, nilFirst bool) {
+	index := ValueOf(key)
+	keys := make([]any, len(data))
+	for i, item := range data {
+		keys[i] = ValueOf(item).PropertyValue(index).Interface()
+	}
+	SortByKeys(data, keys, nilFirst, false)
 }
 
-// Swap is part of sort.Interface.
-func (s genericSortable) Swap(i, j int) {
-	s[i], s[j] = s[j], s[i]
+// SortByKeys sorts data by keys, which holds a key for each element, as
+// SortByProperty sorts by property values. If descending is true, the
+// non-nil keys sort in descending order; the sort is still stable, and nil
+// keys are still placed according to nilFirst. It also reorders keys.
+func SortByKeys(data, keys []any, nilFirst, descending bool) {
+	for i, k := range keys {
+		keys[i] = ToLiquid(k)
+	}
+	if !sameSortKind(keys) {
+		for i, k := range keys {
+			if k != nil {
+				keys[i] = fmt.Sprint(k)
+			}
+		}
+	}
+	sort.Stable(sortableByProperty{data, keys, nilFirst, descending})
 }
 
-// Less is part of sort.Interface.
-func (s genericSortable) Less(i, j int) bool {
-	return Less(s[i], s[j])
+// sortKind is the kind of value that a sort key compares as.
+func sortKind(k any) reflect.Kind {
+	if _, ok := k.(time.Time); ok {
+		return reflect.Struct
+	}
+	switch kind := reflect.ValueOf(k).Kind(); kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	case reflect.Bool, reflect.String:
+		return kind
+	default:
+		return reflect.Invalid
+	}
 }
 
-// SortByProperty sorts maps on their key indices.
-func SortByProperty(data []any, key string, nilFirst bool) {
-	sort.Sort(sortableByProperty{data, key, nilFirst})
+// sameSortKind reports whether the non-nil keys are all numbers, all strings,
+// all booleans, or all times. Only then does Less order them consistently.
+func sameSortKind(keys []any) bool {
+	kind := reflect.Invalid
+	for _, k := range keys {
+		if k == nil {
+			continue
+		}
+		switch sk := sortKind(k); {
+		case sk == reflect.Invalid:
+			return false
+		case kind == reflect.Invalid:
+			kind = sk
+		case sk != kind:
+			return false
+		}
+	}
+	return true
 }
 
 type sortableByProperty struct {
-	data     []any
-	key      string
-	nilFirst bool
+	data       []any
+	keys       []any
+	nilFirst   bool
+	descending bool
 }
 
 // Len is part of sort.Interface.
@@ -45,25 +98,13 @@ func (s sortableByProperty) Len() int {
 
 // Swap is part of sort.Interface.
 func (s sortableByProperty) Swap(i, j int) {
-	data := s.data
-	data[i], data[j] = data[j], data[i]
+	s.data[i], s.data[j] = s.data[j], s.data[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
 }
 
 // Less is part of sort.Interface.
 func (s sortableByProperty) Less(i, j int) bool {
-	// index returns the value at s.key, if in is a map that contains this key
-	index := func(i int) any {
-		value := ToLiquid(s.data[i])
-		rt := reflect.ValueOf(value)
-		if rt.Kind() == reflect.Map && rt.Type().Key().Kind() == reflect.String {
-			elem := rt.MapIndex(reflect.ValueOf(s.key))
-			if elem.IsValid() {
-				return elem.Interface()
-			}
-		}
-		return nil
-	}
-	a, b := index(i), index(j)
+	a, b := s.keys[i], s.keys[j]
 	switch {
 	case a == nil && b == nil:
 		return false
@@ -71,6 +112,9 @@ func (s sortableByProperty) Less(i, j int) bool {
 		return s.nilFirst
 	case b == nil:
 		return !s.nilFirst
+	case s.descending:
+		return Less(b, a)
+	default:
+		return Less(a, b)
 	}
-	return Less(a, b)
 }
@@ -1,7 +1,9 @@
 package values
 
 import (
+	"fmt"
 	"reflect"
+	"sync"
 )
 
 type structValue struct{ wrapperValue }
@@ -15,20 +17,8 @@ func (sv structValue) Contains(elem Value) bool {
 	if !ok {
 		return false
 	}
-	st := reflect.TypeOf(sv.value)
-	if st.Kind() == reflect.Ptr {
-		if _, found := st.MethodByName(name); found {
-			return true
-		}
-		st = st.Elem()
-	}
-	if _, found := st.MethodByName(name); found {
-		return true
-	}
-	if _, found := sv.findField(name); found {
-		return true
-	}
-	return false
+	_, found := structPropertiesOf(reflect.TypeOf(sv.value))[name]
+	return found
 }
 
 func (sv structValue) PropertyValue(index Value) Value {
@@ -36,66 +26,137 @@ func (sv structValue) PropertyValue(index Value) Value {
 	if !ok {
 		return nilValue
 	}
+	p, found := structPropertiesOf(reflect.TypeOf(sv.value))[name]
+	if !found {
+		return nilValue
+	}
 	sr := reflect.ValueOf(sv.value)
-	st := reflect.TypeOf(sv.value)
-	if st.Kind() == reflect.Ptr {
-		if _, found := st.MethodByName(name); found {
-			m := sr.MethodByName(name)
-			return sv.invoke(m)
+	if p.kind == ptrMethodProperty {
+		if sr.Kind() != reflect.Ptr {
+			// A struct value isn't addressable, so call the method on a copy.
+			pv := reflect.New(sr.Type())
+			pv.Elem().Set(sr)
+			sr = pv
 		}
-		st = st.Elem()
+		return sv.invoke(name, sr.Method(p.index[0]))
+	}
+	if sr.Kind() == reflect.Ptr {
 		sr = sr.Elem()
 		if !sr.IsValid() {
 			return nilValue
 		}
 	}
-	if _, ok := st.MethodByName(name); ok {
-		m := sr.MethodByName(name)
-		return sv.invoke(m)
+	if p.kind == methodProperty {
+		return sv.invoke(name, sr.Method(p.index[0]))
 	}
-	if field, ok := sv.findField(name); ok {
-		fv := sr.FieldByName(field.Name)
-		if fv.Kind() == reflect.Func {
-			return sv.invoke(fv)
-		}
-		return ValueOf(fv.Interface())
+	fv := sr.FieldByIndex(p.index)
+	if fv.Kind() == reflect.Func {
+		return sv.invoke(name, fv)
 	}
-	return nilValue
+	return ValueOf(fv.Interface())
 }
 
 const tagKey = "liquid"
 
-// like FieldByName, but obeys `liquid:"name"` tags
-func (sv structValue) findField(name string) (*reflect.StructField, bool) {
-	sr := reflect.TypeOf(sv.value)
-	if sr.Kind() == reflect.Ptr {
-		sr = sr.Elem()
+type propertyKind int
+
+const (
+	ptrMethodProperty propertyKind = iota // a method with a pointer receiver
+	methodProperty                        // a method with a value receiver
+	fieldProperty
+)
+
+// A structProperty locates a property of a struct, or pointer to struct, type.
+type structProperty struct {
+	kind  propertyKind
+	index []int // the method index, or the field index sequence
+}
+
+// structPropertyCache holds the result of structPropertiesOf, by type.
+var structPropertyCache sync.Map // of reflect.Type → map[string]structProperty
+
+// structPropertiesOf returns the properties of typ, a struct type or a pointer
+// to one, by name. It computes these once per type.
+func structPropertiesOf(typ reflect.Type) map[string]structProperty {
+	if props, ok := structPropertyCache.Load(typ); ok {
+		return props.(map[string]structProperty)
+	}
+	props, _ := structPropertyCache.LoadOrStore(typ, findStructProperties(typ))
+	return props.(map[string]structProperty)
+}
+
+// findStructProperties finds the properties of typ. In order of precedence,
+// these are the methods of the struct type, with value or pointer receivers;
+// its fields, including promoted fields, that don't have a `liquid:"name"` tag;
+// and its own fields that do, by their tag name.
+//
+// A method with a pointer receiver is a property of the struct type too, not
+// just of the pointer type. For a struct value, it is called on a copy.
+func findStructProperties(typ reflect.Type) map[string]structProperty {
+	props := map[string]structProperty{}
+	add := func(name string, p structProperty) {
+		if _, found := props[name]; !found {
+			props[name] = p
+		}
+	}
+	st := typ
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	for i, n := 0, st.NumMethod(); i < n; i++ {
+		add(st.Method(i).Name, structProperty{methodProperty, []int{i}})
+	}
+	pt := reflect.PointerTo(st)
+	for i, n := 0, pt.NumMethod(); i < n; i++ {
+		add(pt.Method(i).Name, structProperty{ptrMethodProperty, []int{i}})
 	}
-	if field, ok := sr.FieldByName(name); ok {
-		if _, ok := field.Tag.Lookup(tagKey); !ok {
-			return &field, true
+	for _, f := range reflect.VisibleFields(st) {
+		// FieldByName applies the rules for ambiguous promoted fields
+		if field, ok := st.FieldByName(f.Name); ok {
+			if _, ok := field.Tag.Lookup(tagKey); !ok {
+				add(f.Name, structProperty{fieldProperty, field.Index})
+			}
 		}
 	}
-	for i, n := 0, sr.NumField(); i < n; i++ {
-		field := sr.Field(i)
-		if field.Tag.Get(tagKey) == name {
-			return &field, true
+	for i, n := 0, st.NumField(); i < n; i++ {
+		if field := st.Field(i); field.Tag.Get(tagKey) != "" {
+			add(field.Tag.Get(tagKey), structProperty{fieldProperty, field.Index})
 		}
 	}
-	return nil, false
+	return props
+}
+
+// A MethodError is an error that a method, or a function-valued field, of a
+// struct returns when a template calls it. It is also the error for a Name
+// whose signature a template can't call.
+type MethodError struct {
+	Name string
+	Err  error
 }
 
-func (sv structValue) invoke(fv reflect.Value) Value {
+func (e MethodError) Error() string { return fmt.Sprintf("method %q: %s", e.Name, e.Err) }
+
+// Unwrap returns the error that the method returned.
+func (e MethodError) Unwrap() error { return e.Err }
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// invoke calls fv, the method or function-valued field name. This must take no
+// arguments, and return a value, or a value and an error; otherwise, or if it
+// returns an error, invoke panics with a MethodError.
+func (sv structValue) invoke(name string, fv reflect.Value) Value {
 	if fv.IsNil() {
 		return nilValue
 	}
 	mt := fv.Type()
-	if mt.NumIn() > 0 || mt.NumOut() > 2 {
-		return nilValue
+	if mt.NumIn() > 0 || mt.NumOut() == 0 || mt.NumOut() > 2 || (mt.NumOut() == 2 && mt.Out(1) != errorType) {
+		err := fmt.Errorf("%s is not callable from a template; "+
+			"it must take no arguments and return a value, or a value and an error", mt)
+		panic(MethodError{name, err})
 	}
 	results := fv.Call([]reflect.Value{})
 	if len(results) > 1 && !results[1].IsNil() {
-		panic(results[1].Interface())
+		panic(MethodError{name, results[1].Interface().(error)})
 	}
 	return ValueOf(results[0].Interface())
 }
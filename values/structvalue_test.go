@@ -21,6 +21,11 @@ func (tv testValueStruct) M1() int           { return 3 }
 func (tv testValueStruct) M2() (int, error)  { return 4, nil }
 func (tv testValueStruct) M2e() (int, error) { return 4, errors.New("expected error") }
 
+func (tv testValueStruct) MArg(int) int          { return 5 }
+func (tv testValueStruct) M3() (int, int, error) { return 6, 7, nil }
+func (tv testValueStruct) M2i() (int, int)       { return 8, 9 }
+func (tv testValueStruct) M0()                   {}
+
 func (tv *testValueStruct) PM1() int           { return 3 }
 func (tv *testValueStruct) PM2() (int, error)  { return 4, nil }
 func (tv *testValueStruct) PM2e() (int, error) { return 4, errors.New("expected error") }
@@ -63,6 +68,30 @@ func TestValue_struct(t *testing.T) {
 	require.Equal(t, 4, s.PropertyValue(ValueOf("M2")).Interface())
 	require.Panics(t, func() { s.PropertyValue(ValueOf("M2e")) })
 	require.Equal(t, -1, s.IndexValue(ValueOf("F")).Interface())
+
+	// method errors
+	methodError := func(name string) (err MethodError) {
+		defer func() {
+			e, ok := recover().(MethodError)
+			require.Truef(t, ok, name)
+			err = e
+		}()
+		s.PropertyValue(ValueOf(name))
+		return
+	}
+	require.EqualError(t, methodError("M2e"), `method "M2e": expected error`)
+	require.EqualError(t, methodError("F2e"), `method "F2e": expected error`)
+	for _, name := range []string{"MArg", "M3", "M2i", "M0"} {
+		err := methodError(name)
+		require.Equal(t, name, err.Name)
+		require.Contains(t, err.Error(), "is not callable from a template")
+	}
+
+	// pointer methods are called on a copy
+	require.True(t, s.Contains(ValueOf("PM1")))
+	require.Equal(t, 3, s.PropertyValue(ValueOf("PM1")).Interface())
+	require.Equal(t, 4, s.PropertyValue(ValueOf("PM2")).Interface())
+	require.Panics(t, func() { s.PropertyValue(ValueOf("PM2e")) })
 }
 
 func TestValue_struct_ptr(t *testing.T) {
@@ -89,3 +118,76 @@ func TestValue_struct_ptr(t *testing.T) {
 	require.Equal(t, 4, p.PropertyValue(ValueOf("PM2")).Interface())
 	require.Panics(t, func() { p.PropertyValue(ValueOf("PM2e")) })
 }
+
+type testAuthor struct{ First, Last string }
+
+func (a *testAuthor) FullName() string { return a.First + " " + a.Last }
+
+type testPost struct {
+	Author testAuthor
+}
+
+func TestValue_struct_ptr_method_chain(t *testing.T) {
+	for _, v := range []any{
+		&testPost{testAuthor{"Ann", "Lee"}},
+		testPost{testAuthor{"Ann", "Lee"}},
+	} {
+		author := ValueOf(v).PropertyValue(ValueOf("Author"))
+		require.Equal(t, "Ann Lee", author.PropertyValue(ValueOf("FullName")).Interface())
+	}
+	require.Nil(t, ValueOf((*testPost)(nil)).PropertyValue(ValueOf("Author")).Interface())
+}
+
+type testEmbeddedStruct struct {
+	E      int
+	Shadow int
+	Tagged int `liquid:"tagged"`
+}
+
+func (testEmbeddedStruct) EM() string { return "embedded method" }
+
+type testOuterStruct struct {
+	testEmbeddedStruct
+	Shadow string
+}
+
+func TestValue_struct_embedded(t *testing.T) {
+	for _, v := range []any{
+		testOuterStruct{testEmbeddedStruct{E: 1, Shadow: 2, Tagged: 3}, "outer"},
+		&testOuterStruct{testEmbeddedStruct{E: 1, Shadow: 2, Tagged: 3}, "outer"},
+	} {
+		s := ValueOf(v)
+		require.True(t, s.Contains(ValueOf("E")))
+		require.Equal(t, 1, s.PropertyValue(ValueOf("E")).Interface())
+		require.Equal(t, "outer", s.PropertyValue(ValueOf("Shadow")).Interface())
+		require.Equal(t, "embedded method", s.PropertyValue(ValueOf("EM")).Interface())
+		// tags are only recognized on the fields of the struct itself
+		require.False(t, s.Contains(ValueOf("tagged")))
+		require.False(t, s.Contains(ValueOf("Tagged")))
+		require.False(t, s.Contains(ValueOf("Missing")))
+		require.Nil(t, s.PropertyValue(ValueOf("Missing")).Interface())
+	}
+	require.Nil(t, ValueOf((*testOuterStruct)(nil)).PropertyValue(ValueOf("E")).Interface())
+}
+
+func BenchmarkStructValue_PropertyValue(b *testing.B) {
+	s := ValueOf(&testValueStruct{F: 1, Nest: &testValueStruct{}, Renamed: 2})
+	names := []Value{ValueOf("F"), ValueOf("Nest"), ValueOf("name"), ValueOf("M1"), ValueOf("PM1"), ValueOf("Missing")}
+	b.ResetTimer()
+	for range b.N {
+		for _, name := range names {
+			s.PropertyValue(name)
+		}
+	}
+}
+
+func BenchmarkStructValue_Contains(b *testing.B) {
+	s := ValueOf(testValueStruct{})
+	names := []Value{ValueOf("F"), ValueOf("name"), ValueOf("M1"), ValueOf("Missing")}
+	b.ResetTimer()
+	for range b.N {
+		for _, name := range names {
+			s.Contains(name)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package values
+
+import (
+	"time"
+)
+
+// A timeValue wraps a time.Time. Comparison is handled by Equal and Less,
+// which recognize time.Time operands.
+type timeValue struct{ wrapperValue }
+
+func (tv timeValue) PropertyValue(iv Value) Value {
+	t := tv.value.(time.Time)
+	switch iv.Interface() {
+	case "year":
+		return ValueOf(t.Year())
+	case "month":
+		return ValueOf(int(t.Month()))
+	case "day":
+		return ValueOf(t.Day())
+	case "hour":
+		return ValueOf(t.Hour())
+	case "minute":
+		return ValueOf(t.Minute())
+	case "second":
+		return ValueOf(t.Second())
+	case "weekday":
+		// Sunday is 0, as in Ruby's Time#wday
+		return ValueOf(int(t.Weekday()))
+	}
+	return nilValue
+}
+
+// timeOperands returns a and b as times, if one is a time.Time and the other
+// is either a time.Time or a string that ParseDate recognizes.
+func timeOperands(a, b any) (time.Time, time.Time, bool) {
+	ta, aok := a.(time.Time)
+	tb, bok := b.(time.Time)
+	switch {
+	case aok && bok:
+		return ta, tb, true
+	case aok:
+		if s, ok := b.(string); ok {
+			if t, err := ParseDate(s); err == nil {
+				return ta, t, true
+			}
+		}
+	case bok:
+		if s, ok := a.(string); ok {
+			if t, err := ParseDate(s); err == nil {
+				return t, tb, true
+			}
+		}
+	}
+	return zeroTime, zeroTime, false
+}
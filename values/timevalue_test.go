@@ -0,0 +1,57 @@
+package values
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValue_time(t *testing.T) {
+	t1 := time.Date(2015, 7, 17, 15, 4, 5, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	tv := ValueOf(t1)
+	require.Equal(t, t1, tv.Interface())
+
+	require.True(t, tv.Equal(ValueOf(t1)))
+	require.False(t, tv.Equal(ValueOf(t2)))
+	require.True(t, tv.Less(ValueOf(t2)))
+	require.False(t, ValueOf(t2).Less(tv))
+	require.False(t, tv.Less(tv))
+
+	// same instant, different locations
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	require.True(t, tv.Equal(ValueOf(t1.In(loc))))
+	require.False(t, tv.Less(ValueOf(t1.In(loc))))
+	require.False(t, ValueOf(t1.In(loc)).Less(tv))
+
+	// pointers
+	require.True(t, ValueOf(&t1).Equal(tv))
+
+	// property access
+	require.Equal(t, 2015, tv.PropertyValue(ValueOf("year")).Interface())
+	require.Equal(t, 7, tv.PropertyValue(ValueOf("month")).Interface())
+	require.Equal(t, 17, tv.PropertyValue(ValueOf("day")).Interface())
+	require.Equal(t, 15, tv.PropertyValue(ValueOf("hour")).Interface())
+	require.Equal(t, 4, tv.PropertyValue(ValueOf("minute")).Interface())
+	require.Equal(t, 5, tv.PropertyValue(ValueOf("second")).Interface())
+	require.Equal(t, 5, tv.PropertyValue(ValueOf("weekday")).Interface())
+	require.Nil(t, tv.PropertyValue(ValueOf("Year")).Interface())
+	require.Nil(t, tv.IndexValue(ValueOf(0)).Interface())
+}
+
+func TestValue_time_string(t *testing.T) {
+	t1 := time.Date(2015, 7, 17, 15, 4, 5, 0, time.UTC)
+	tv := ValueOf(t1)
+	require.True(t, tv.Equal(ValueOf("2015-07-17T15:04:05Z")))
+	require.True(t, tv.Equal(ValueOf("2015-07-17T10:04:05-05:00")))
+	require.True(t, tv.Less(ValueOf("2016-01-01T00:00:00Z")))
+	require.False(t, tv.Less(ValueOf("2015-01-01T00:00:00Z")))
+	require.True(t, ValueOf("2015-01-01T00:00:00Z").Less(tv))
+	require.True(t, ValueOf("2015-07-17T15:04:05Z").Equal(tv))
+
+	// unparseable strings don't compare
+	require.False(t, tv.Equal(ValueOf("not a date")))
+	require.False(t, tv.Less(ValueOf("not a date")))
+	require.False(t, ValueOf("not a date").Less(tv))
+}
@@ -2,8 +2,10 @@ package values
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	yaml "gopkg.in/yaml.v2"
@@ -14,6 +16,11 @@ type Value interface {
 	// Value retrieval
 	Interface() any
 	Int() int
+	// Int64 returns the value of an integer, and whether the value is an integer.
+	Int64() (int64, bool)
+	// Float64 returns the value of a number (integer or float) as a float64,
+	// and whether the value is a number.
+	Float64() (float64, bool)
 
 	// Comparison
 	Equal(Value) bool
@@ -43,14 +50,22 @@ func ValueOf(value any) Value { //nolint: gocyclo
 	case 1:
 		return oneValue
 	}
+	// registered types
+	if fn, ok := findValueConverter(value); ok {
+		return fn(value)
+	}
 	// interfaces
 	switch v := value.(type) {
 	case drop:
 		return &dropWrapper{d: v}
 	case yaml.MapSlice:
 		return mapSliceValue{slice: v}
+	case time.Time:
+		return timeValue{wrapperValue{v}}
 	case Value:
 		return v
+	case PropertyDrop:
+		return propertyDropValue{wrapperValue{value}}
 	}
 	switch reflect.TypeOf(value).Kind() {
 	case reflect.Ptr:
@@ -58,7 +73,7 @@ func ValueOf(value any) Value { //nolint: gocyclo
 		if rv.IsNil() {
 			return nilValue
 		}
-		if rv.Type().Elem().Kind() == reflect.Struct {
+		if rv.Type().Elem().Kind() == reflect.Struct && rv.Type().Elem() != timeType {
 			return structValue{wrapperValue{value}}
 		}
 		return ValueOf(rv.Elem().Interface())
@@ -89,6 +104,8 @@ func (v valueEmbed) Less(Value) bool           { return false }
 func (v valueEmbed) IndexValue(Value) Value    { return nilValue }
 func (v valueEmbed) Contains(Value) bool       { return false }
 func (v valueEmbed) Int() int                  { panic(conversionError("", v, reflect.TypeOf(1))) }
+func (v valueEmbed) Int64() (int64, bool)      { return 0, false }
+func (v valueEmbed) Float64() (float64, bool)  { return 0, false }
 func (v valueEmbed) PropertyValue(Value) Value { return nilValue }
 func (v valueEmbed) Test() bool                { return true }
 
@@ -104,12 +121,38 @@ func (v wrapperValue) PropertyValue(Value) Value { return nilValue }
 func (v wrapperValue) Test() bool                { return v.value != nil && v.value != false }
 
 func (v wrapperValue) Int() int {
-	if n, ok := v.value.(int); ok {
-		return n
+	if n, ok := v.Int64(); ok {
+		return int(n)
 	}
 	panic(conversionError("", v.value, reflect.TypeOf(1)))
 }
 
+func (v wrapperValue) Int64() (int64, bool) {
+	rv := reflect.ValueOf(v.value)
+	switch {
+	case isIntKind(rv.Kind()):
+		return rv.Int(), true
+	case isUintKind(rv.Kind()) && rv.Uint() <= math.MaxInt64:
+		return int64(rv.Uint()), true // #nosec G115
+	default:
+		return 0, false
+	}
+}
+
+func (v wrapperValue) Float64() (float64, bool) {
+	rv := reflect.ValueOf(v.value)
+	switch {
+	case isIntKind(rv.Kind()):
+		return float64(rv.Int()), true
+	case isUintKind(rv.Kind()):
+		return float64(rv.Uint()), true
+	case isFloatKind(rv.Kind()):
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
 // interned values
 var (
 	nilValue   = wrapperValue{nil}
@@ -126,6 +169,8 @@ type (
 	stringValue struct{ wrapperValue }
 )
 
+// Contains compares the elements to ev with Equal, so that 2 and 2.0 match
+// but 2 and "2" do not.
 func (av arrayValue) Contains(ev Value) bool {
 	ar := reflect.ValueOf(av.value)
 	e := ev.Interface()
@@ -217,12 +262,18 @@ func (mv mapValue) PropertyValue(iv Value) Value {
 	}
 }
 
+// Contains reports whether substr is a substring. As in Shopify, a substr that
+// isn't a string is compared by its string form, so "10.5%" contains 10.5;
+// nil is contained in no string.
 func (sv stringValue) Contains(substr Value) bool {
-	s, ok := substr.Interface().(string)
-	if !ok {
-		s = fmt.Sprint(substr.Interface())
+	switch s := substr.Interface().(type) {
+	case nil:
+		return false
+	case string:
+		return strings.Contains(sv.value.(string), s)
+	default:
+		return strings.Contains(sv.value.(string), fmt.Sprint(s))
 	}
-	return strings.Contains(sv.value.(string), s)
 }
 
 func (sv stringValue) PropertyValue(iv Value) Value {
@@ -231,3 +282,27 @@ func (sv stringValue) PropertyValue(iv Value) Value {
 	}
 	return nilValue
 }
+
+// HasProperty reports whether the map, struct, or drop v defines the named
+// property. It reports true for other values, since their properties, such as
+// array.size, are computed rather than looked up.
+func HasProperty(v Value, name string) bool {
+	if dw, ok := v.(*dropWrapper); ok {
+		v = dw.Resolve()
+	}
+	switch v := v.(type) {
+	case mapValue:
+		mr := reflect.ValueOf(v.value)
+		kr := reflect.ValueOf(name)
+		if !kr.Type().ConvertibleTo(mr.Type().Key()) {
+			return false
+		}
+		return name == sizeKey || mr.MapIndex(kr.Convert(mr.Type().Key())).IsValid()
+	case mapSliceValue:
+		return name == sizeKey || v.Contains(ValueOf(name))
+	case structValue, propertyDropValue:
+		return v.Contains(ValueOf(name))
+	default:
+		return true
+	}
+}
@@ -41,7 +41,42 @@ func TestValue_Int(t *testing.T) {
 	nv := ValueOf(nil)
 	iv := ValueOf(123)
 	require.Equal(t, 123, iv.Int())
+	require.Equal(t, 123, ValueOf(int64(123)).Int())
+	require.Equal(t, 123, ValueOf(uint8(123)).Int())
 	require.Panics(t, func() { nv.Int() })
+	require.Panics(t, func() { ValueOf(1.5).Int() })
+	require.Panics(t, func() { ValueOf("1").Int() })
+}
+
+func TestValue_Int64(t *testing.T) {
+	for _, v := range []any{123, int8(123), int16(123), int32(123), int64(123), uint(123), uint8(123), uint16(123), uint32(123), uint64(123)} {
+		n, ok := ValueOf(v).Int64()
+		require.Truef(t, ok, "%T", v)
+		require.Equalf(t, int64(123), n, "%T", v)
+	}
+	for _, v := range []any{nil, true, 1.5, "1", []int{1}, map[string]int{}, uint64(1) << 63} {
+		_, ok := ValueOf(v).Int64()
+		require.Falsef(t, ok, "%#v", v)
+	}
+	_, ok := ValueOf(yaml.MapSlice{}).Int64()
+	require.False(t, ok)
+}
+
+func TestValue_Float64(t *testing.T) {
+	for _, v := range []any{2, int8(2), int64(2), uint(2), uint64(2), float32(2), 2.0} {
+		f, ok := ValueOf(v).Float64()
+		require.Truef(t, ok, "%T", v)
+		require.Equalf(t, 2.0, f, "%T", v)
+	}
+	f, ok := ValueOf(1.5).Float64()
+	require.True(t, ok)
+	require.Equal(t, 1.5, f)
+	for _, v := range []any{nil, false, "1.5", []float64{1}} {
+		_, ok := ValueOf(v).Float64()
+		require.Falsef(t, ok, "%#v", v)
+	}
+	_, ok = ValueOf(yaml.MapSlice{}).Float64()
+	require.False(t, ok)
 }
 
 func TestValue_IndexValue(t *testing.T) {
@@ -134,14 +169,39 @@ func TestValue_Contains(t *testing.T) {
 
 	require.True(t, ValueOf([]any{nil}).Contains(ValueOf(nil)))
 
+	// array elements are compared as the == operator compares them
+	nv := ValueOf([]any{1, 2, 3})
+	require.True(t, nv.Contains(ValueOf(2.0)))
+	require.True(t, nv.Contains(ValueOf(int64(3))))
+	require.True(t, nv.Contains(ValueOf(uint8(1))))
+	require.False(t, nv.Contains(ValueOf(2.5)))
+	require.False(t, nv.Contains(ValueOf("2")))
+	require.True(t, ValueOf([]float64{1.5, 2}).Contains(ValueOf(2)))
+	require.True(t, ValueOf([]any{1.5, int64(2), "x"}).Contains(ValueOf(2)))
+	require.False(t, ValueOf([]any{"1", "2"}).Contains(ValueOf(2)))
+
 	// string
 	sv := ValueOf("seafood")
 	require.True(t, sv.Contains(ValueOf("foo")))
 	require.False(t, sv.Contains(ValueOf("bar")))
 	require.False(t, sv.Contains(ValueOf(nil)))
 
+	require.False(t, ValueOf("<nil>").Contains(ValueOf(nil)))
+	require.True(t, sv.Contains(ValueOf("")))
+
+	// multibyte
+	mv := ValueOf("Grüße, 世界")
+	require.True(t, mv.Contains(ValueOf("üß")))
+	require.True(t, mv.Contains(ValueOf("世界")))
+	require.False(t, mv.Contains(ValueOf("ü界")))
+	require.False(t, mv.Contains(ValueOf("ue")))
+
 	// string contains stringifies its argument
 	require.True(t, ValueOf("seaf00d").Contains(ValueOf(0)))
+	require.True(t, ValueOf("price: 10.5").Contains(ValueOf(10.5)))
+	require.True(t, ValueOf("1,2,3").Contains(ValueOf(2)))
+	require.False(t, ValueOf("1,2,3").Contains(ValueOf(4)))
+	require.True(t, ValueOf("is true").Contains(ValueOf(true)))
 
 	// map
 	hv := ValueOf(map[string]any{"key": "value"})